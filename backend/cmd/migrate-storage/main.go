@@ -0,0 +1,47 @@
+// Command migrate-storage copies every stored document file from one storage
+// backend to another. Both backends are configured from the usual
+// environment variables, e.g.
+//
+//	S3_ENDPOINT=http://localhost:9000 S3_BUCKET=documents \
+//	S3_ACCESS_KEY=minioadmin S3_SECRET_KEY=minioadmin \
+//	go run ./cmd/migrate-storage -from local -to s3
+//
+// Documents reference files by storage key, so after migrating set
+// STORAGE_BACKEND to the new backend and restart the server.
+//
+// With ENCRYPTION_KEY set, files are decrypted on the way out and encrypted
+// again with fresh data keys on the way in. Plain text files written before
+// encryption was enabled end up encrypted, so migrating from a local copy is
+// also how existing files are encrypted.
+package main
+
+import (
+	"flag"
+	"log"
+	"vendor-management/storage"
+)
+
+func main() {
+	from := flag.String("from", "local", "source backend (local or s3)")
+	to := flag.String("to", "s3", "destination backend (local or s3)")
+	deleteSource := flag.Bool("delete", false, "delete each file from the source once copied")
+	flag.Parse()
+
+	if *from == *to {
+		log.Fatal("Source and destination backends must differ")
+	}
+	source, err := storage.New(*from)
+	if err != nil {
+		log.Fatalf("Error opening %s storage: %v", *from, err)
+	}
+	destination, err := storage.New(*to)
+	if err != nil {
+		log.Fatalf("Error opening %s storage: %v", *to, err)
+	}
+
+	copied, err := storage.Migrate(source, destination, *deleteSource)
+	if err != nil {
+		log.Fatalf("Migration stopped after %d files: %v", copied, err)
+	}
+	log.Printf("Migrated %d files from %s to %s storage", copied, *from, *to)
+}
@@ -0,0 +1,37 @@
+// Command rotate-keys rewraps the data key of every stored document with the
+// active master key. To rotate, generate a new key with
+//
+//	openssl rand -base64 32
+//
+// then make it the active key and retire the old one, e.g.
+//
+//	ENCRYPTION_KEY=2025-06:<new key> ENCRYPTION_RETIRED_KEYS=2024-01:<old key> \
+//	go run ./cmd/rotate-keys
+//
+// Restart the server with the same settings. Once the command has finished,
+// the retired key can be dropped from ENCRYPTION_RETIRED_KEYS. Only the small
+// key envelopes are rewritten; the encrypted files stay as they are.
+package main
+
+import (
+	"log"
+	"vendor-management/config"
+	"vendor-management/storage"
+)
+
+func main() {
+	store, err := storage.New(config.StorageBackend)
+	if err != nil {
+		log.Fatalf("Error opening %s storage: %v", config.StorageBackend, err)
+	}
+	encrypted, ok := store.(*storage.EncryptedStore)
+	if !ok {
+		log.Fatal("ENCRYPTION_KEY is not set")
+	}
+
+	rewrapped, err := encrypted.Rewrap()
+	if err != nil {
+		log.Fatalf("Rotation stopped after %d data keys: %v", rewrapped, err)
+	}
+	log.Printf("Rewrapped %d data keys", rewrapped)
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings are read from environment variables at startup and fall back to
+// defaults suitable for local development
+
+// TrashRetention is how long soft-deleted records stay restorable before the
+// purge job removes them permanently
+var TrashRetention = daysEnv("TRASH_RETENTION_DAYS", 30)
+
+// ReviewInterval is how often each active vendor should receive a
+// performance review before a reminder is sent
+var ReviewInterval = daysEnv("REVIEW_INTERVAL_DAYS", 90)
+
+// StandardWorkHours is the length of a full working day, used to convert
+// day credits to billable hours and to measure overtime
+var StandardWorkHours = intEnv("STANDARD_WORK_HOURS", 8)
+
+// ComplianceWarning is how far ahead of expiry a required document is
+// reported as expiring
+var ComplianceWarning = daysEnv("COMPLIANCE_WARNING_DAYS", 30)
+
+// POAlertThresholds are the purchase order utilization percentages at which
+// admins and project owners are alerted, given as a comma-separated list
+var POAlertThresholds = intListEnv("PO_ALERT_THRESHOLDS", []int{75, 90, 100})
+
+// MaxUploadSize is the largest document file accepted, in bytes
+var MaxUploadSize = int64(intEnv("MAX_UPLOAD_SIZE_MB", 10)) << 20
+
+// StorageBackend selects where document files are kept: local or s3
+var StorageBackend = stringEnv("STORAGE_BACKEND", "local")
+
+// StorageLocalRoot is the directory used by the local storage backend
+var StorageLocalRoot = stringEnv("STORAGE_LOCAL_ROOT", "uploads")
+
+// S3 settings for the s3 storage backend. Any S3-compatible service works;
+// path-style addressing is needed for MinIO and most self-hosted servers.
+var (
+	S3Endpoint  = stringEnv("S3_ENDPOINT", "https://s3.amazonaws.com")
+	S3Region    = stringEnv("S3_REGION", "us-east-1")
+	S3Bucket    = stringEnv("S3_BUCKET", "")
+	S3AccessKey = stringEnv("S3_ACCESS_KEY", "")
+	S3SecretKey = stringEnv("S3_SECRET_KEY", "")
+	S3PathStyle = boolEnv("S3_PATH_STYLE", true)
+)
+
+// ShareLinkSecret signs document share links. Changing it invalidates every
+// link handed out. When it is unset a random secret is generated, so links
+// stop working when the server restarts.
+var ShareLinkSecret = secretEnv("SHARE_LINK_SECRET")
+
+// ShareLinkDefaultAge and ShareLinkMaxAge bound how long a document share
+// link stays valid
+var (
+	ShareLinkDefaultAge = time.Duration(intEnv("SHARE_LINK_DEFAULT_HOURS", 72)) * time.Hour
+	ShareLinkMaxAge     = daysEnv("SHARE_LINK_MAX_DAYS", 30)
+)
+
+// PublicBaseURL is prefixed to links sent outside the app, such as document
+// share links. The request's own host is used when it is empty.
+var PublicBaseURL = stringEnv("PUBLIC_BASE_URL", "")
+
+// ScannerBackend selects the malware scanner run on uploads: clamd, or stub,
+// which only detects the EICAR test file and is meant for development. When
+// it is empty nothing is scanned, so uploads are stored but withheld as
+// awaiting a scan until a scanner is configured.
+var ScannerBackend = stringEnv("SCANNER_BACKEND", "")
+
+// ClamdAddress and ClamdTimeout configure the clamd scanner. The address is
+// tcp://host:port or unix:///path/to/clamd.sock.
+var (
+	ClamdAddress = stringEnv("CLAMD_ADDRESS", "tcp://localhost:3310")
+	ClamdTimeout = time.Duration(intEnv("CLAMD_TIMEOUT_SECONDS", 60)) * time.Second
+)
+
+// PreviewWorkers is how many previews are generated at the same time
+var PreviewWorkers = intEnv("PREVIEW_WORKERS", 2)
+
+// PDFRenderer is the path of pdftoppm, used to render the first page of PDFs
+// for previews. When empty, PDF previews show the first embedded image, which
+// for scanned documents is the scanned page.
+var PDFRenderer = stringEnv("PDF_RENDERER", "")
+
+// EncryptionKey is the master key that wraps the data key of every stored
+// document, written as <id>:<base64 of 32 bytes>. Files are stored in plain
+// text when it is empty.
+var EncryptionKey = stringEnv("ENCRYPTION_KEY", "")
+
+// EncryptionRetiredKeys lists earlier master keys, comma-separated, that are
+// kept until the rotate-keys command has rewrapped every data key
+var EncryptionRetiredKeys = stringEnv("ENCRYPTION_RETIRED_KEYS", "")
+
+func stringEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func boolEnv(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid value for %s, using default %t: %v", key, fallback, err)
+		return fallback
+	}
+	return b
+}
+
+func intEnv(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid value for %s, using default %d: %v", key, fallback, err)
+		return fallback
+	}
+	return n
+}
+
+func intListEnv(key string, fallback []int) []int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	list := make([]int, 0)
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			log.Printf("Invalid value for %s, using default %v: %v", key, fallback, err)
+			return fallback
+		}
+		list = append(list, n)
+	}
+	sort.Ints(list)
+	return list
+}
+
+func daysEnv(key string, fallback int) time.Duration {
+	return time.Duration(intEnv(key, fallback)) * 24 * time.Hour
+}
+
+// secretEnv reads a signing secret, generating a random one for this run of
+// the server when it is unset
+func secretEnv(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Failed to generate a secret for %s: %v", key, err)
+	}
+	log.Printf("WARNING: %s is not set, using a random secret that changes on every restart", key)
+	return hex.EncodeToString(secret)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 	"time"
 	"vendor-management/models"
 
@@ -13,6 +14,18 @@ type CreateAssetRequest struct {
 	Type         string `json:"type"`
 	SerialNumber string `json:"serialNumber"`
 	VendorID     string `json:"vendor_id"`
+
+	CustomFields map[string]interface{} `json:"customFields"`
+}
+
+// PatchAssetRequest holds the asset fields editable through PatchAsset.
+// Assignment changes go through AssignAsset and ReturnAsset instead.
+type PatchAssetRequest struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	SerialNumber string `json:"serialNumber"`
+
+	CustomFields map[string]interface{} `json:"customFields"`
 }
 
 type AssignAssetRequest struct {
@@ -26,16 +39,24 @@ func CreateAsset(c *gin.Context) {
 		return
 	}
 
+	customFields, err := validateCustomFields(assetEntity, req.CustomFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	asset := &models.Asset{
 		ID:           generateID(),
 		Name:         req.Name,
 		Type:         req.Type,
 		SerialNumber: req.SerialNumber,
 		Status:       "available", // Default status
+		Version:      1,
+		CustomFields: customFields,
 	}
 
 	if req.VendorID != "" {
-		vendor, exists := models.Vendors[req.VendorID]
+		vendor, exists := lookupVendor(req.VendorID)
 		if !exists {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
 			return
@@ -46,20 +67,70 @@ func CreateAsset(c *gin.Context) {
 	}
 
 	models.Assets[asset.ID] = asset
+	setETag(c, asset.Version)
 	c.JSON(http.StatusCreated, asset)
 }
 
+// lookupAsset returns the asset unless it does not exist or is in the trash
+func lookupAsset(id string) (*models.Asset, bool) {
+	asset, exists := models.Assets[id]
+	if !exists || asset.DeletedAt != nil {
+		return nil, false
+	}
+	return asset, true
+}
+
+// assetFilterFromQuery builds the asset filter shared by ListAssets and
+// ExportAssets from the status, type, assignedTo and cf.<key> custom field
+// query parameters
+func assetFilterFromQuery(c *gin.Context) (func(*models.Asset) bool, error) {
+	status := c.Query("status")
+	assetType := c.Query("type")
+	assignedTo := c.Query("assignedTo")
+	matchCustomFields, err := customFieldFilterFromQuery(c, assetEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(a *models.Asset) bool {
+		if a.DeletedAt != nil {
+			return false
+		}
+		if status != "" && !strings.EqualFold(a.Status, status) {
+			return false
+		}
+		if assetType != "" && !strings.EqualFold(a.Type, assetType) {
+			return false
+		}
+		if assignedTo != "" && a.AssignedTo != assignedTo {
+			return false
+		}
+		return matchCustomFields(a.CustomFields)
+	}, nil
+}
+
 func ListAssets(c *gin.Context) {
+	match, err := assetFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	assets := make([]*models.Asset, 0)
 	for _, asset := range models.Assets {
-		assets = append(assets, asset)
+		if match(asset) {
+			assets = append(assets, asset)
+		}
 	}
 	c.JSON(http.StatusOK, assets)
 }
 
 func UpdateAsset(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
 	id := c.Param("id")
-	asset, exists := models.Assets[id]
+	asset, exists := lookupAsset(id)
 	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Asset not found"})
 		return
@@ -71,21 +142,79 @@ func UpdateAsset(c *gin.Context) {
 		return
 	}
 
+	saveAsset(c, asset, &PatchAssetRequest{
+		Name:         req.Name,
+		Type:         req.Type,
+		SerialNumber: req.SerialNumber,
+		CustomFields: req.CustomFields,
+	})
+}
+
+// PatchAsset applies a JSON Merge Patch to the asset's editable fields
+func PatchAsset(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	id := c.Param("id")
+	asset, exists := lookupAsset(id)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Asset not found"})
+		return
+	}
+
+	current := PatchAssetRequest{
+		Name:         asset.Name,
+		Type:         asset.Type,
+		SerialNumber: asset.SerialNumber,
+		CustomFields: asset.CustomFields,
+	}
+	var req PatchAssetRequest
+	if err := bindMergePatch(c, current, &req); err != nil {
+		patchError(c, err)
+		return
+	}
+
+	saveAsset(c, asset, &req)
+}
+
+// saveAsset applies an update to the asset once its If-Match precondition
+// holds and bumps its version
+func saveAsset(c *gin.Context, asset *models.Asset, req *PatchAssetRequest) {
+	if !checkIfMatch(c, asset.Version) {
+		return
+	}
+
+	customFields, err := validateCustomFields(assetEntity, req.CustomFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	asset.Name = req.Name
 	asset.Type = req.Type
 	asset.SerialNumber = req.SerialNumber
+	asset.CustomFields = customFields
+	asset.Version++
 
+	setETag(c, asset.Version)
 	c.JSON(http.StatusOK, asset)
 }
 
 func AssignAsset(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
 	id := c.Param("id")
-	asset, exists := models.Assets[id]
+	asset, exists := lookupAsset(id)
 	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Asset not found"})
 		return
 	}
 
+	if !checkIfMatch(c, asset.Version) {
+		return
+	}
+
 	if asset.Status != "available" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Asset is not available"})
 		return
@@ -97,7 +226,7 @@ func AssignAsset(c *gin.Context) {
 		return
 	}
 
-	vendor, exists := models.Vendors[req.VendorID]
+	vendor, exists := lookupVendor(req.VendorID)
 	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
 		return
@@ -106,20 +235,29 @@ func AssignAsset(c *gin.Context) {
 	asset.AssignedTo = req.VendorID
 	asset.AssignedAt = time.Now()
 	asset.Status = "assigned"
+	asset.Version++
 
 	vendor.Assets = append(vendor.Assets, *asset)
 
+	setETag(c, asset.Version)
 	c.JSON(http.StatusOK, asset)
 }
 
 func ReturnAsset(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
 	id := c.Param("id")
-	asset, exists := models.Assets[id]
+	asset, exists := lookupAsset(id)
 	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Asset not found"})
 		return
 	}
 
+	if !checkIfMatch(c, asset.Version) {
+		return
+	}
+
 	if asset.Status != "assigned" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Asset is not assigned"})
 		return
@@ -139,7 +277,9 @@ func ReturnAsset(c *gin.Context) {
 	asset.ReturnedAt = time.Now()
 	asset.Status = "available"
 	asset.AssignedTo = ""
+	asset.Version++
 
+	setETag(c, asset.Version)
 	c.JSON(http.StatusOK, asset)
 }
 
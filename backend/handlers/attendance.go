@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 	"vendor-management/models"
@@ -9,8 +10,10 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func ListAttendance(c *gin.Context) {
-	// Optional date range filters
+// attendanceFilterFromQuery builds the attendance filter shared by
+// ListAttendance and ExportAttendance from the optional startDate and
+// endDate query parameters
+func attendanceFilterFromQuery(c *gin.Context) (func(*models.Attendance) bool, error) {
 	startDate := c.Query("startDate")
 	endDate := c.Query("endDate")
 
@@ -19,29 +22,41 @@ func ListAttendance(c *gin.Context) {
 	if startDate != "" {
 		start, err = time.Parse("2006-01-02", startDate)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
-			return
+			return nil, errors.New("Invalid start date format")
 		}
 	}
 	if endDate != "" {
 		end, err = time.Parse("2006-01-02", endDate)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
-			return
+			return nil, errors.New("Invalid end date format")
+		}
+	}
+
+	return func(a *models.Attendance) bool {
+		if startDate != "" && a.Date.Before(start) {
+			return false
 		}
+		if endDate != "" && a.Date.After(end) {
+			return false
+		}
+		return true
+	}, nil
+}
+
+func ListAttendance(c *gin.Context) {
+	match, err := attendanceFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	result := make(map[string][]models.Attendance)
 	for vendorID, attendances := range models.AttendanceRecords {
 		filtered := make([]models.Attendance, 0)
 		for _, a := range attendances {
-			if startDate != "" && a.Date.Before(start) {
-				continue
-			}
-			if endDate != "" && a.Date.After(end) {
-				continue
+			if match(a) {
+				filtered = append(filtered, *a)
 			}
-			filtered = append(filtered, *a)
 		}
 		if len(filtered) > 0 {
 			result[vendorID] = filtered
@@ -55,7 +70,7 @@ func GetVendorAttendance(c *gin.Context) {
 	vendorID := c.Param("vendorId")
 
 	// Verify vendor exists
-	if _, exists := models.Vendors[vendorID]; !exists {
+	if _, exists := lookupVendor(vendorID); !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
 		return
 	}
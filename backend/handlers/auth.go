@@ -107,6 +107,7 @@ func HandleSignup(c *gin.Context) {
 		Password:  string(hashedPassword),
 		Role:      models.Role(req.Role),
 		CreatedAt: time.Now(),
+		Version:   1,
 	}
 
 	models.Users[user.ID] = user
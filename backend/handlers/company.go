@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateCompanyRequest struct {
+	LegalName   string              `json:"legalName" binding:"required"`
+	TradeName   string              `json:"tradeName"`
+	TaxIDs      []models.TaxID      `json:"taxIds"`
+	Addresses   []models.Address    `json:"addresses"`
+	Contacts    []models.Contact    `json:"contacts"`
+	BankDetails *models.BankAccount `json:"bankDetails"`
+	Status      string              `json:"status" binding:"omitempty,oneof=active inactive"`
+}
+
+// lookupCompany returns the company with the given ID
+func lookupCompany(id string) (*models.Company, bool) {
+	company, exists := models.Companies[id]
+	return company, exists
+}
+
+// applyCompanyRequest validates the nested company details and copies them
+// onto the company
+func applyCompanyRequest(company *models.Company, req *CreateCompanyRequest) error {
+	for _, taxID := range req.TaxIDs {
+		if taxID.Type == "" || taxID.Value == "" {
+			return errors.New("Tax IDs require a type and value")
+		}
+	}
+	for _, address := range req.Addresses {
+		if address.Line1 == "" || address.City == "" || address.Country == "" {
+			return errors.New("Addresses require line1, city and country")
+		}
+	}
+	for _, contact := range req.Contacts {
+		if contact.Name == "" {
+			return errors.New("Contacts require a name")
+		}
+	}
+	if b := req.BankDetails; b != nil && (b.AccountName == "" || b.AccountNumber == "" || b.BankName == "") {
+		return errors.New("Bank details require account name, account number and bank name")
+	}
+
+	company.LegalName = req.LegalName
+	company.TradeName = req.TradeName
+	company.TaxIDs = nonNilSlice(req.TaxIDs)
+	company.Addresses = nonNilSlice(req.Addresses)
+	company.Contacts = nonNilSlice(req.Contacts)
+	company.BankDetails = req.BankDetails
+	if req.Status != "" {
+		company.Status = req.Status
+	}
+	return nil
+}
+
+func nonNilSlice[T any](items []T) []T {
+	if items == nil {
+		return make([]T, 0)
+	}
+	return items
+}
+
+// companyWorkers returns the workers supplied by a company, excluding those
+// in the trash
+func companyWorkers(companyID string) []*models.Vendor {
+	workers := make([]*models.Vendor, 0)
+	for _, vendor := range models.Vendors {
+		if vendor.CompanyID == companyID && vendor.DeletedAt == nil {
+			workers = append(workers, vendor)
+		}
+	}
+	return workers
+}
+
+func CreateCompany(c *gin.Context) {
+	var req CreateCompanyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	company := &models.Company{
+		ID:        generateID(),
+		Status:    "active",
+		CreatedAt: time.Now(),
+		Version:   1,
+	}
+	if err := applyCompanyRequest(company, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	models.Companies[company.ID] = company
+	setETag(c, company.Version)
+	c.JSON(http.StatusCreated, company)
+}
+
+func ListCompanies(c *gin.Context) {
+	status := c.Query("status")
+	companies := make([]*models.Company, 0)
+	for _, company := range models.Companies {
+		if status != "" && !strings.EqualFold(company.Status, status) {
+			continue
+		}
+		companies = append(companies, company)
+	}
+	c.JSON(http.StatusOK, companies)
+}
+
+func GetCompany(c *gin.Context) {
+	company, exists := lookupCompany(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
+		return
+	}
+
+	setETag(c, company.Version)
+	c.JSON(http.StatusOK, company)
+}
+
+func UpdateCompany(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	company, exists := lookupCompany(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
+		return
+	}
+
+	var req CreateCompanyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !checkIfMatch(c, company.Version) {
+		return
+	}
+
+	updated := *company
+	if err := applyCompanyRequest(&updated, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	updated.Version++
+	*company = updated
+
+	// Keep the legal name mirrored on the company's workers
+	for _, worker := range companyWorkers(company.ID) {
+		if worker.CompanyName != company.LegalName {
+			worker.CompanyName = company.LegalName
+			worker.Version++
+			recordVendorRevision(c, worker)
+		}
+	}
+
+	setETag(c, company.Version)
+	c.JSON(http.StatusOK, company)
+}
+
+func ListCompanyWorkers(c *gin.Context) {
+	company, exists := lookupCompany(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, companyWorkers(company.ID))
+}
+
+// GetCompanySummary rolls up headcount, assets, documents and attendance
+// across a company's workers. Attendance honours the optional startDate and
+// endDate query parameters.
+func GetCompanySummary(c *gin.Context) {
+	company, exists := lookupCompany(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
+		return
+	}
+
+	matchAttendance, err := attendanceFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workers := companyWorkers(company.ID)
+	workerIDs := make(map[string]bool)
+	activeWorkers := 0
+	for _, worker := range workers {
+		workerIDs[worker.ID] = true
+		if worker.Status == "active" {
+			activeWorkers++
+		}
+	}
+
+	assetsByType := make(map[string]int)
+	assignedAssets := 0
+	for _, asset := range models.Assets {
+		if asset.DeletedAt == nil && asset.Status == "assigned" && workerIDs[asset.AssignedTo] {
+			assetsByType[asset.Type]++
+			assignedAssets++
+		}
+	}
+
+	companyDocs, workerDocs := 0, 0
+	for _, doc := range models.Documents {
+		if doc.DeletedAt != nil {
+			continue
+		}
+		if doc.CompanyID == company.ID {
+			companyDocs++
+		} else if workerIDs[doc.VendorID] {
+			workerDocs++
+		}
+	}
+
+	var presentDays float64
+	attendanceRecords := 0
+	daysByWorker := make(map[string]float64)
+	for workerID := range workerIDs {
+		for _, record := range models.AttendanceRecords[workerID] {
+			if !matchAttendance(record) {
+				continue
+			}
+			attendanceRecords++
+			presentDays += float64(record.PresentDay)
+			daysByWorker[workerID] += float64(record.PresentDay)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"companyId": company.ID,
+		"headcount": gin.H{
+			"total":  len(workers),
+			"active": activeWorkers,
+		},
+		"assets": gin.H{
+			"assigned": assignedAssets,
+			"byType":   assetsByType,
+		},
+		"documents": gin.H{
+			"company": companyDocs,
+			"worker":  workerDocs,
+		},
+		"attendance": gin.H{
+			"records":      attendanceRecords,
+			"presentDays":  presentDays,
+			"byWorkerDays": daysByWorker,
+		},
+	})
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"vendor-management/models"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateComplianceRuleRequest struct {
+	VendorType   string `json:"vendorType"`
+	DocumentType string `json:"documentType" binding:"required"`
+	Critical     bool   `json:"critical"`
+}
+
+func ListComplianceRules(c *gin.Context) {
+	rules := make([]*models.ComplianceRule, 0, len(models.ComplianceRules))
+	for _, rule := range models.ComplianceRules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].VendorType != rules[j].VendorType {
+			return rules[i].VendorType < rules[j].VendorType
+		}
+		return rules[i].DocumentType < rules[j].DocumentType
+	})
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func CreateComplianceRule(c *gin.Context) {
+	var req CreateComplianceRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	docType, err := parseDocumentType(req.DocumentType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, rule := range models.ComplianceRules {
+		if strings.EqualFold(rule.VendorType, req.VendorType) && rule.DocumentType == docType {
+			c.JSON(http.StatusConflict, gin.H{"error": "A rule for this vendor type and document type already exists"})
+			return
+		}
+	}
+
+	rule := &models.ComplianceRule{
+		ID:           generateID(),
+		VendorType:   req.VendorType,
+		DocumentType: docType,
+		Critical:     req.Critical,
+	}
+	models.ComplianceRules[rule.ID] = rule
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func DeleteComplianceRule(c *gin.Context) {
+	if _, exists := models.ComplianceRules[c.Param("id")]; !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Compliance rule not found"})
+		return
+	}
+
+	delete(models.ComplianceRules, c.Param("id"))
+	c.Status(http.StatusNoContent)
+}
+
+// ListCompliance returns the latest compliance status of vendors, by default
+// only the non-compliant ones. Pass state=expiring, compliant or all to
+// widen the list.
+func ListCompliance(c *gin.Context) {
+	state := c.DefaultQuery("state", string(models.NonCompliant))
+
+	statuses := make([]gin.H, 0)
+	for _, status := range models.ComplianceStatuses {
+		if state != "all" && string(status.State) != state {
+			continue
+		}
+		vendor, exists := lookupVendor(status.VendorID)
+		if !exists {
+			continue
+		}
+		statuses = append(statuses, gin.H{
+			"vendorId":    vendor.ID,
+			"companyName": vendor.CompanyName,
+			"vendorType":  vendor.Type,
+			"status":      vendor.Status,
+			"compliance":  status,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i]["companyName"].(string) < statuses[j]["companyName"].(string)
+	})
+
+	c.JSON(http.StatusOK, statuses)
+}
+
+// RunComplianceCheck runs the daily compliance check immediately, for
+// example after changing the rules
+func RunComplianceCheck(c *gin.Context) {
+	utils.CheckCompliance()
+	ListCompliance(c)
+}
+
+// GetVendorCompliance evaluates one vendor's documents without suspending
+// or reinstating it
+func GetVendorCompliance(c *gin.Context) {
+	vendor, exists := lookupVendor(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	status, criticalLapse := utils.EvaluateCompliance(vendor, time.Now())
+	c.JSON(http.StatusOK, gin.H{
+		"compliance":    status,
+		"criticalLapse": criticalLapse,
+		"rules":         utils.ComplianceRulesFor(vendor.Type),
+	})
+}
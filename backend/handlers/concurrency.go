@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+var errUnsupportedPatchType = errors.New("Content-Type must be application/merge-patch+json")
+
+// versionMu is held by handlers that check If-Match from before they read the
+// record until they have bumped its version, so two requests sending the same
+// ETag cannot both pass the check
+var versionMu sync.Mutex
+
+func etagFor(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// setETag exposes the record version to clients for use with If-Match
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", etagFor(version))
+}
+
+// checkIfMatch enforces the If-Match precondition against the current record
+// version. The precondition is opt-in: requests without the header, or with
+// "*", are allowed through and overwrite whatever is current, so clients that
+// need to detect lost updates must send the ETag they last read. On a
+// mismatch it writes 412 and returns false. Callers must hold versionMu.
+func checkIfMatch(c *gin.Context, version int) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" || header == "*" {
+		return true
+	}
+
+	current := etagFor(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == current {
+			return true
+		}
+	}
+
+	setETag(c, version)
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Record was modified by another request"})
+	return false
+}
+
+// bindMergePatch applies the request body as a JSON Merge Patch to the
+// current representation and decodes the result into dst. Fields not present
+// in dst are rejected so read-only attributes cannot be patched.
+func bindMergePatch(c *gin.Context, current interface{}, dst interface{}) error {
+	if contentType := c.GetHeader("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+			return errUnsupportedPatchType
+		}
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	original, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	merged, err := utils.MergePatch(original, patch)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(merged)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(dst)
+}
+
+// patchError writes the response for an error returned by bindMergePatch
+func patchError(c *gin.Context, err error) {
+	if err == errUnsupportedPatchType {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
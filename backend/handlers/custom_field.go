@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	vendorEntity = "vendor"
+	assetEntity  = "asset"
+)
+
+// customFieldQueryPrefix marks list filter parameters that match custom
+// field values, e.g. ?cf.gstNumber=29ABCDE1234F1Z5
+const customFieldQueryPrefix = "cf."
+
+var customFieldKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+type CreateCustomFieldRequest struct {
+	Entity    string   `json:"entity" binding:"required,oneof=vendor asset"`
+	Key       string   `json:"key" binding:"required"`
+	Label     string   `json:"label" binding:"required"`
+	Type      string   `json:"type" binding:"required,oneof=text number date enum boolean"`
+	Required  bool     `json:"required"`
+	Options   []string `json:"options"`
+	Min       *float64 `json:"min"`
+	Max       *float64 `json:"max"`
+	Pattern   string   `json:"pattern"`
+	MaxLength int      `json:"maxLength" binding:"min=0"`
+}
+
+// customFieldsFor returns the field definitions for an entity ordered by key
+func customFieldsFor(entity string) []*models.CustomFieldDefinition {
+	defs := make([]*models.CustomFieldDefinition, 0)
+	for _, def := range models.CustomFields {
+		if def.Entity == entity {
+			defs = append(defs, def)
+		}
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Key < defs[j].Key })
+	return defs
+}
+
+// validateCustomFields checks submitted values against the entity's field
+// definitions and returns a new map holding the normalized values
+func validateCustomFields(entity string, values map[string]interface{}) (map[string]interface{}, error) {
+	defs := make(map[string]*models.CustomFieldDefinition)
+	for _, def := range customFieldsFor(entity) {
+		defs[def.Key] = def
+	}
+
+	result := make(map[string]interface{})
+	for key, value := range values {
+		def, exists := defs[key]
+		if !exists {
+			return nil, fmt.Errorf("Unknown custom field: %s", key)
+		}
+		if value == nil {
+			continue
+		}
+		normalized, err := validateCustomFieldValue(def, value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for custom field %s: %v", key, err)
+		}
+		result[key] = normalized
+	}
+
+	for key, def := range defs {
+		if _, ok := result[key]; def.Required && !ok {
+			return nil, fmt.Errorf("Custom field %s is required", key)
+		}
+	}
+	return result, nil
+}
+
+func validateCustomFieldValue(def *models.CustomFieldDefinition, value interface{}) (interface{}, error) {
+	switch def.Type {
+	case models.TextField:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string")
+		}
+		if def.MaxLength > 0 && len(s) > def.MaxLength {
+			return nil, fmt.Errorf("longer than %d characters", def.MaxLength)
+		}
+		if def.Pattern != "" && !regexp.MustCompile(def.Pattern).MatchString(s) {
+			return nil, fmt.Errorf("does not match pattern %s", def.Pattern)
+		}
+		return s, nil
+	case models.NumberField:
+		n, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected a number")
+		}
+		if def.Min != nil && n < *def.Min {
+			return nil, fmt.Errorf("less than %v", *def.Min)
+		}
+		if def.Max != nil && n > *def.Max {
+			return nil, fmt.Errorf("greater than %v", *def.Max)
+		}
+		return n, nil
+	case models.DateField:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a date string")
+		}
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return nil, fmt.Errorf("expected format YYYY-MM-DD")
+		}
+		return s, nil
+	case models.EnumField:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string")
+		}
+		for _, option := range def.Options {
+			if option == s {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("must be one of %s", strings.Join(def.Options, ", "))
+	case models.BooleanField:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected a boolean")
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("unsupported field type %s", def.Type)
+}
+
+// formatCustomFieldValue renders a stored value for filters and exports
+func formatCustomFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// customFieldFilterFromQuery builds a filter from cf.<key> query parameters.
+// Values are compared case-insensitively against the formatted field value.
+func customFieldFilterFromQuery(c *gin.Context, entity string) (func(map[string]interface{}) bool, error) {
+	wanted := make(map[string]string)
+	for param, values := range c.Request.URL.Query() {
+		if !strings.HasPrefix(param, customFieldQueryPrefix) {
+			continue
+		}
+		key := strings.TrimPrefix(param, customFieldQueryPrefix)
+		found := false
+		for _, def := range customFieldsFor(entity) {
+			if def.Key == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown custom field: %s", key)
+		}
+		wanted[key] = values[0]
+	}
+
+	return func(fields map[string]interface{}) bool {
+		for key, value := range wanted {
+			if !strings.EqualFold(formatCustomFieldValue(fields[key]), value) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func ListCustomFields(c *gin.Context) {
+	entity := c.Query("entity")
+	defs := make([]*models.CustomFieldDefinition, 0)
+	if entity != "" {
+		defs = customFieldsFor(entity)
+	} else {
+		defs = append(defs, customFieldsFor(vendorEntity)...)
+		defs = append(defs, customFieldsFor(assetEntity)...)
+	}
+	c.JSON(http.StatusOK, defs)
+}
+
+func CreateCustomField(c *gin.Context) {
+	var req CreateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !customFieldKeyPattern.MatchString(req.Key) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Key must start with a letter and contain only letters, digits and underscores"})
+		return
+	}
+	for _, def := range customFieldsFor(req.Entity) {
+		if def.Key == req.Key {
+			c.JSON(http.StatusConflict, gin.H{"error": "Custom field already exists"})
+			return
+		}
+	}
+
+	fieldType := models.CustomFieldType(req.Type)
+	if fieldType == models.EnumField && len(req.Options) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Enum fields require options"})
+		return
+	}
+	if req.Pattern != "" {
+		if _, err := regexp.Compile(req.Pattern); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pattern"})
+			return
+		}
+	}
+	if req.Min != nil && req.Max != nil && *req.Min > *req.Max {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Min must not exceed max"})
+		return
+	}
+
+	def := &models.CustomFieldDefinition{
+		ID:        generateID(),
+		Entity:    req.Entity,
+		Key:       req.Key,
+		Label:     req.Label,
+		Type:      fieldType,
+		Required:  req.Required,
+		Options:   req.Options,
+		Min:       req.Min,
+		Max:       req.Max,
+		Pattern:   req.Pattern,
+		MaxLength: req.MaxLength,
+		CreatedAt: time.Now(),
+	}
+
+	models.CustomFields[def.ID] = def
+	c.JSON(http.StatusCreated, def)
+}
+
+// DeleteCustomField removes the definition and its stored values
+func DeleteCustomField(c *gin.Context) {
+	id := c.Param("id")
+	def, exists := models.CustomFields[id]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+		return
+	}
+
+	if def.Entity == vendorEntity {
+		for _, vendor := range models.Vendors {
+			if _, ok := vendor.CustomFields[def.Key]; ok {
+				vendor.CustomFields = withoutKey(vendor.CustomFields, def.Key)
+			}
+		}
+	} else {
+		for _, asset := range models.Assets {
+			if _, ok := asset.CustomFields[def.Key]; ok {
+				asset.CustomFields = withoutKey(asset.CustomFields, def.Key)
+			}
+		}
+	}
+
+	delete(models.CustomFields, id)
+	c.Status(http.StatusNoContent)
+}
+
+// withoutKey copies the map without the key, leaving the original intact for
+// revision snapshots that share it
+func withoutKey(fields map[string]interface{}, key string) map[string]interface{} {
+	result := make(map[string]interface{})
+	for k, v := range fields {
+		if k != key {
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateDepartmentRequest struct {
+	Code    string  `json:"code" binding:"required"`
+	Name    string  `json:"name" binding:"required"`
+	OwnerID string  `json:"ownerId"`
+	Budget  float64 `json:"budget" binding:"min=0"`
+}
+
+// validateOwner checks that an optional owner refers to an existing user
+func validateOwner(ownerID string) error {
+	if ownerID == "" {
+		return nil
+	}
+	if _, exists := models.Users[ownerID]; !exists {
+		return errors.New("Owner not found")
+	}
+	return nil
+}
+
+// namesEntity reports whether a free-text name refers to a department or
+// project by its name or code
+func namesEntity(name, entityName, code string) bool {
+	return strings.EqualFold(entityName, name) || strings.EqualFold(code, name)
+}
+
+// resolveDepartment links a vendor to a managed department, either by ID or
+// by matching the free-text name against department names and codes. A name
+// given with an ID must refer to the same department. Until any departments
+// are defined, unmatched names are kept as free text.
+func resolveDepartment(id, name string) (*models.Department, error) {
+	if id != "" {
+		department, exists := models.Departments[id]
+		if !exists {
+			return nil, errors.New("Department not found")
+		}
+		if name != "" && !namesEntity(name, department.Name, department.Code) {
+			return nil, errors.New("Department name does not match departmentId")
+		}
+		return department, nil
+	}
+
+	for _, department := range models.Departments {
+		if namesEntity(name, department.Name, department.Code) {
+			return department, nil
+		}
+	}
+	if len(models.Departments) > 0 && name != "" {
+		return nil, errors.New("Unknown department: " + name)
+	}
+	return nil, nil
+}
+
+func CreateDepartment(c *gin.Context) {
+	var req CreateDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	department := &models.Department{
+		ID:        generateID(),
+		CreatedAt: time.Now(),
+		Version:   1,
+	}
+	if status, err := applyDepartmentRequest(department, &req); err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	models.Departments[department.ID] = department
+	setETag(c, department.Version)
+	c.JSON(http.StatusCreated, department)
+}
+
+// applyDepartmentRequest validates the request and copies it onto the
+// department, returning the HTTP status to use on failure
+func applyDepartmentRequest(department *models.Department, req *CreateDepartmentRequest) (int, error) {
+	for _, other := range models.Departments {
+		if other.ID == department.ID {
+			continue
+		}
+		if strings.EqualFold(other.Code, req.Code) || strings.EqualFold(other.Name, req.Name) {
+			return http.StatusConflict, errors.New("Department code or name already exists")
+		}
+	}
+	if err := validateOwner(req.OwnerID); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	department.Code = req.Code
+	department.Name = req.Name
+	department.OwnerID = req.OwnerID
+	department.Budget = req.Budget
+	return 0, nil
+}
+
+func ListDepartments(c *gin.Context) {
+	departments := make([]*models.Department, 0)
+	for _, department := range models.Departments {
+		departments = append(departments, department)
+	}
+	c.JSON(http.StatusOK, departments)
+}
+
+func GetDepartment(c *gin.Context) {
+	department, exists := models.Departments[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Department not found"})
+		return
+	}
+
+	setETag(c, department.Version)
+	c.JSON(http.StatusOK, department)
+}
+
+func UpdateDepartment(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	department, exists := models.Departments[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Department not found"})
+		return
+	}
+
+	var req CreateDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !checkIfMatch(c, department.Version) {
+		return
+	}
+
+	if status, err := applyDepartmentRequest(department, &req); err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	department.Version++
+
+	// Keep the department name mirrored on assigned vendors
+	for _, vendor := range models.Vendors {
+		if vendor.DepartmentID == department.ID && vendor.Department != department.Name {
+			vendor.Department = department.Name
+			vendor.Version++
+			recordVendorRevision(c, vendor)
+		}
+	}
+
+	setETag(c, department.Version)
+	c.JSON(http.StatusOK, department)
+}
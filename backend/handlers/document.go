@@ -1,152 +1,367 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
+	"log"
+	"mime"
 	"net/http"
-	"os"
-	"path/filepath"
 	"time"
 	"vendor-management/models"
+	"vendor-management/storage"
+	"vendor-management/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-const uploadDir = "uploads"
+var errVendorNotFound = errors.New("Vendor not found")
 
-func init() {
-	// Create uploads directory if it doesn't exist
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		panic(fmt.Sprintf("Failed to create upload directory: %v", err))
+// UploadDocument stores a worker document when vendorId is given, or a
+// company-level document when companyId is given instead. adminOnly=true
+// keeps a worker document out of the vendor's view.
+func UploadDocument(c *gin.Context) {
+	if !limitUploadSize(c) {
+		return
 	}
-}
 
-func UploadDocument(c *gin.Context) {
 	vendorID := c.PostForm("vendorId")
-	docType := c.PostForm("type")
+	companyID := c.PostForm("companyId")
+	docType, err := parseDocumentType(c.PostForm("type"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	vendor, exists := models.Vendors[vendorID]
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+	var vendor *models.Vendor
+	switch {
+	case vendorID != "" && companyID != "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Specify either vendorId or companyId, not both"})
+		return
+	case vendorID != "":
+		var exists bool
+		vendor, exists = lookupVendor(vendorID)
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+			return
+		}
+	case companyID != "":
+		if _, exists := lookupCompany(companyID); !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "vendorId or companyId is required"})
 		return
 	}
 
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File upload failed"})
+	doc, ok := createDocument(c, vendor, companyID, docType, models.DocumentApproved)
+	if !ok {
 		return
 	}
+	doc.AdminOnly = c.PostForm("adminOnly") == "true"
+	if vendor != nil {
+		utils.SyncVendorDocument(doc)
+		recheckCompliance(vendor)
+	}
 
-	// Generate unique filename
-	ext := filepath.Ext(file.Filename)
-	filename := fmt.Sprintf("%s_%s%s", vendorID, generateID(), ext)
-	filepath := filepath.Join(uploadDir, filename)
+	c.JSON(http.StatusCreated, doc)
+}
 
-	if err := c.SaveUploadedFile(file, filepath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
-		return
+// createDocument stores the uploaded file as the first version of a new
+// document belonging to the vendor, or to the company when vendor is nil.
+// It writes the error response itself and reports whether it succeeded.
+func createDocument(c *gin.Context, vendor *models.Vendor, companyID string, docType models.DocumentType, status models.DocumentStatus) (*models.Document, bool) {
+	vendorID, ownerID := "", companyID
+	if vendor != nil {
+		vendorID, ownerID = vendor.ID, vendor.ID
+	}
+
+	version, code, err := saveDocumentVersion(c, ownerID, docType, 1)
+	if err != nil {
+		uploadFailed(c, code, err)
+		return nil, false
 	}
 
 	doc := &models.Document{
 		ID:         generateID(),
 		VendorID:   vendorID,
-		Name:       file.Filename,
+		CompanyID:  companyID,
 		Type:       docType,
-		FilePath:   filepath,
-		UploadedAt: time.Now(),
+		UploadedAt: version.UploadedAt,
+		Status:     status,
+		Versions:   []models.DocumentVersion{*version},
 	}
+	applyCurrentVersion(doc, 1)
 
 	models.Documents[doc.ID] = doc
-	vendor.Documents = append(vendor.Documents, *doc)
+	if vendor != nil {
+		vendor.Documents = append(vendor.Documents, *doc)
+	}
+	indexDocument(doc)
+	queuePreview(&doc.Versions[0])
+	return doc, true
+}
 
-	c.JSON(http.StatusCreated, doc)
+// parseDocumentValidity parses the optional validFrom and expiresAt dates of
+// an uploaded document
+func parseDocumentValidity(from, to string) (time.Time, time.Time, error) {
+	var validFrom, expiresAt time.Time
+	var err error
+	if from != "" {
+		validFrom, err = time.Parse("2006-01-02", from)
+		if err != nil {
+			return validFrom, expiresAt, errors.New("Invalid valid from date format")
+		}
+	}
+	if to != "" {
+		expiresAt, err = time.Parse("2006-01-02", to)
+		if err != nil {
+			return validFrom, expiresAt, errors.New("Invalid expiry date format")
+		}
+		if expiresAt.Before(validFrom) {
+			return validFrom, expiresAt, errors.New("Expiry date must not be before valid from date")
+		}
+	}
+	return validFrom, expiresAt, nil
 }
 
-func ListDocuments(c *gin.Context) {
+// recheckCompliance re-evaluates a vendor after its documents change, so a
+// suspension is lifted as soon as the missing document is uploaded. Vendors
+// not yet seen by the daily compliance job are left for it.
+func recheckCompliance(vendor *models.Vendor) {
+	if _, checked := models.ComplianceStatuses[vendor.ID]; checked {
+		utils.CheckVendorCompliance(vendor)
+	}
+}
+
+// lookupDocument returns the document unless it does not exist or is in the
+// trash
+func lookupDocument(id string) (*models.Document, bool) {
+	doc, exists := models.Documents[id]
+	if !exists || doc.DeletedAt != nil {
+		return nil, false
+	}
+	return doc, true
+}
+
+// documentFilterFromQuery builds the document filter shared by ListDocuments,
+// ExportDocuments and ExportDocumentBundle from the vendorId, companyId,
+// departmentId, projectId, type and status query parameters. companyId
+// matches company-level documents only; departmentId and projectId match
+// the documents of vendors in the department or on the project.
+func documentFilterFromQuery(c *gin.Context) (func(*models.Document) bool, error) {
 	vendorID := c.Query("vendorId")
+	companyID := c.Query("companyId")
+	docType := c.Query("type")
+	status := c.Query("status")
+	departmentID := c.Query("departmentId")
+	projectID := c.Query("projectId")
+
 	if vendorID != "" {
-		vendor, exists := models.Vendors[vendorID]
-		if !exists {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
-			return
+		if _, exists := lookupVendor(vendorID); !exists {
+			return nil, errVendorNotFound
+		}
+	}
+
+	return func(d *models.Document) bool {
+		if d.DeletedAt != nil {
+			return false
+		}
+		vendor, ok := models.Vendors[d.VendorID]
+		if ok && vendor.DeletedAt != nil {
+			return false
+		}
+		if departmentID != "" && (!ok || vendor.DepartmentID != departmentID) {
+			return false
+		}
+		if projectID != "" && (!ok || vendor.ProjectID != projectID) {
+			return false
 		}
-		c.JSON(http.StatusOK, vendor.Documents)
+		if vendorID != "" && d.VendorID != vendorID {
+			return false
+		}
+		if companyID != "" && d.CompanyID != companyID {
+			return false
+		}
+		if docType != "" && string(d.Type) != docType {
+			return false
+		}
+		if status != "" && string(d.Status) != status {
+			return false
+		}
+		return true
+	}, nil
+}
+
+func ListDocuments(c *gin.Context) {
+	match, err := documentFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Return all documents if no vendor ID specified
 	docs := make([]*models.Document, 0)
 	for _, doc := range models.Documents {
-		docs = append(docs, doc)
+		if match(doc) {
+			docs = append(docs, doc)
+		}
 	}
 	c.JSON(http.StatusOK, docs)
 }
 
 func GetDocument(c *gin.Context) {
 	id := c.Param("id")
-	doc, exists := models.Documents[id]
+	doc, exists := lookupDocument(id)
 	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
 		return
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(doc.FilePath); os.IsNotExist(err) {
+	serveStoredFile(c, &doc.Versions[doc.CurrentVersion-1], false)
+}
+
+// serveStoredFile streams the file of a document version from the storage
+// backend, honouring range and conditional requests. Files not yet found
+// clean by the malware scanner are refused, and the file is checked against
+// its checksum first so that a corrupted file is never served. attachment
+// asks the browser to save the file under its name rather than display it.
+func serveStoredFile(c *gin.Context, version *models.DocumentVersion, attachment bool) {
+	if !checkScanStatus(c, version) {
+		return
+	}
+
+	key, name := version.StorageKey, version.Name
+	obj, err := storage.Store.Open(key)
+	if errors.Is(err, storage.ErrNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer obj.Close()
+
+	if err := utils.VerifyChecksum(obj, version.Checksum); err != nil {
+		log.Printf("Refusing to serve file %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "File failed integrity check"})
+		return
+	}
+
+	if version.ContentType != "" {
+		c.Header("Content-Type", version.ContentType)
+	}
+	if attachment {
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	}
+	http.ServeContent(c.Writer, c.Request, name, obj.ModTime(), obj)
+}
+
+// checkScanStatus refuses files the malware scanner has not found clean,
+// reporting whether the request may proceed
+func checkScanStatus(c *gin.Context, version *models.DocumentVersion) bool {
+	switch version.ScanStatus {
+	case models.ScanClean:
+		return true
+	case models.ScanInfected:
+		c.JSON(http.StatusForbidden, gin.H{"error": "File is quarantined as malware"})
+	default:
+		c.JSON(http.StatusConflict, gin.H{"error": "File is awaiting a malware scan"})
+	}
+	return false
+}
+
+type DocumentVisibilityRequest struct {
+	AdminOnly bool `json:"adminOnly"`
+}
+
+// SetDocumentVisibility hides a document from its vendor or shows it again
+func SetDocumentVisibility(c *gin.Context) {
+	doc, exists := lookupDocument(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
 
-	c.File(doc.FilePath)
+	var req DocumentVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	doc.AdminOnly = req.AdminOnly
+	utils.SyncVendorDocument(doc)
+	c.JSON(http.StatusOK, doc)
 }
 
+// DeleteDocument moves the document to the trash. The file stays in storage
+// until the purge job removes it after the retention period.
 func DeleteDocument(c *gin.Context) {
 	id := c.Param("id")
-	doc, exists := models.Documents[id]
+	doc, exists := lookupDocument(id)
 	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
 		return
 	}
 
-	// Remove document from vendor's documents
-	if vendor, exists := models.Vendors[doc.VendorID]; exists {
-		newDocs := make([]models.Document, 0)
-		for _, d := range vendor.Documents {
-			if d.ID != doc.ID {
-				newDocs = append(newDocs, d)
-			}
-		}
-		vendor.Documents = newDocs
+	if utils.UnderLegalHold(doc) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Document is under legal hold"})
+		return
 	}
 
-	// Delete file from disk
-	if err := os.Remove(doc.FilePath); err != nil && !os.IsNotExist(err) {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete file"})
-		return
+	markDeleted(c, &doc.DeletedAt, &doc.DeletedBy)
+
+	// Remove document from vendor's documents
+	if vendor, exists := models.Vendors[doc.VendorID]; exists {
+		removeVendorDocument(vendor, doc.ID)
+		recheckCompliance(vendor)
 	}
 
-	delete(models.Documents, id)
 	c.Status(http.StatusNoContent)
 }
 
-func GetMyDocuments(c *gin.Context) {
-	userID, _ := c.Get("userId")
-	user, exists := models.Users[userID.(string)]
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		return
+func removeVendorDocument(vendor *models.Vendor, docID string) {
+	newDocs := make([]models.Document, 0)
+	for _, d := range vendor.Documents {
+		if d.ID != docID {
+			newDocs = append(newDocs, d)
+		}
 	}
+	vendor.Documents = newDocs
+}
 
-	if user.Role != models.VendorRole {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only vendors can view their documents"})
+// GetMyDocuments lists the vendor's own documents. Suspended vendors can still
+// see them, with any rejection reasons, so that they can put them in order.
+func GetMyDocuments(c *gin.Context) {
+	user, ok := currentVendorUser(c, "Only vendors can view their documents")
+	if !ok {
 		return
 	}
 
-	// Find vendor's documents
-	for _, vendor := range models.Vendors {
-		if vendor.UserID == user.ID {
-			c.JSON(http.StatusOK, vendor.Documents)
-			return
+	// Find the documents of the vendor's current record, leaving out those
+	// kept from the vendor
+	docs := make([]models.Document, 0)
+	if vendor, exists := activeVendorOf(user); exists {
+		for _, doc := range vendor.Documents {
+			if !doc.AdminOnly {
+				docs = append(docs, doc)
+			}
 		}
 	}
 
-	c.JSON(http.StatusOK, []models.Document{})
+	c.JSON(http.StatusOK, docs)
+}
+
+// GetIntegrityReport returns the result of the latest integrity scan
+func GetIntegrityReport(c *gin.Context) {
+	if models.LastIntegrityReport == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No integrity scan has run yet"})
+		return
+	}
+	c.JSON(http.StatusOK, models.LastIntegrityReport)
+}
+
+// RunIntegrityScan verifies every stored document file now rather than
+// waiting for the nightly scan
+func RunIntegrityScan(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.ScanDocumentIntegrity())
 }
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"vendor-management/models"
+	"vendor-management/storage"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bundleEntry describes one document in a bundle manifest
+type bundleEntry struct {
+	Path        string    `json:"path"`
+	DocumentID  string    `json:"documentId"`
+	VendorID    string    `json:"vendorId,omitempty"`
+	CompanyID   string    `json:"companyId,omitempty"`
+	Owner       string    `json:"owner"`
+	Type        string    `json:"type"`
+	Name        string    `json:"name"`
+	Version     int       `json:"version"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	UploadedAt  time.Time `json:"uploadedAt"`
+	ValidFrom   string    `json:"validFrom"`
+	ExpiresAt   string    `json:"expiresAt"`
+	Status      string    `json:"status"` // included, missing, checksum_mismatch, not_scanned or quarantined
+}
+
+var bundleManifestColumns = []exportColumn[*bundleEntry]{
+	{"path", func(e *bundleEntry) string { return e.Path }},
+	{"documentId", func(e *bundleEntry) string { return e.DocumentID }},
+	{"vendorId", func(e *bundleEntry) string { return e.VendorID }},
+	{"companyId", func(e *bundleEntry) string { return e.CompanyID }},
+	{"owner", func(e *bundleEntry) string { return e.Owner }},
+	{"type", func(e *bundleEntry) string { return e.Type }},
+	{"name", func(e *bundleEntry) string { return e.Name }},
+	{"version", func(e *bundleEntry) string { return strconv.Itoa(e.Version) }},
+	{"contentType", func(e *bundleEntry) string { return e.ContentType }},
+	{"size", func(e *bundleEntry) string { return strconv.FormatInt(e.Size, 10) }},
+	{"checksum", func(e *bundleEntry) string { return e.Checksum }},
+	{"uploadedAt", func(e *bundleEntry) string { return formatExportTime(e.UploadedAt) }},
+	{"validFrom", func(e *bundleEntry) string { return e.ValidFrom }},
+	{"expiresAt", func(e *bundleEntry) string { return e.ExpiresAt }},
+	{"status", func(e *bundleEntry) string { return e.Status }},
+}
+
+// bundlePathSegment makes a name safe to use as one folder or file name
+func bundlePathSegment(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+// bundleFolder places a document under vendors/<vendor>/<type> or
+// companies/<company>/<type>. The ID keeps owners with the same name apart.
+func bundleFolder(doc *models.Document) (string, string) {
+	if vendor, exists := models.Vendors[doc.VendorID]; exists {
+		owner := fmt.Sprintf("%s (%s)", vendor.CompanyName, vendor.ID)
+		return path.Join("vendors", bundlePathSegment(owner), string(doc.Type)), vendor.CompanyName
+	}
+	owner := doc.CompanyID
+	if company, exists := models.Companies[doc.CompanyID]; exists {
+		owner = company.LegalName
+	}
+	folder := fmt.Sprintf("%s (%s)", owner, doc.CompanyID)
+	return path.Join("companies", bundlePathSegment(folder), string(doc.Type)), owner
+}
+
+// ExportDocumentBundle streams the current version of every matching document
+// as a ZIP archive with manifest.csv and manifest.json at its root. Files are
+// copied from storage one at a time, so only the manifest is held in memory.
+func ExportDocumentBundle(c *gin.Context) {
+	match, err := documentFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries := make([]*bundleEntry, 0)
+	docs := make(map[*bundleEntry]*models.Document)
+	for _, doc := range models.Documents {
+		if !match(doc) {
+			continue
+		}
+		folder, owner := bundleFolder(doc)
+		entry := &bundleEntry{
+			Path:        path.Join(folder, bundlePathSegment(doc.Name)),
+			DocumentID:  doc.ID,
+			VendorID:    doc.VendorID,
+			CompanyID:   doc.CompanyID,
+			Owner:       owner,
+			Type:        string(doc.Type),
+			Name:        doc.Name,
+			Version:     doc.CurrentVersion,
+			ContentType: doc.ContentType,
+			Size:        doc.Size,
+			Checksum:    doc.Checksum,
+			UploadedAt:  doc.UploadedAt,
+			ValidFrom:   formatExportDate(doc.ValidFrom),
+			ExpiresAt:   formatExportDate(doc.ExpiresAt),
+		}
+		entries = append(entries, entry)
+		docs[entry] = doc
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].DocumentID < entries[j].DocumentID
+	})
+
+	// Number repeated file names within a folder as name (2).pdf and so on
+	used := make(map[string]bool)
+	for _, entry := range entries {
+		base, ext := strings.TrimSuffix(entry.Path, path.Ext(entry.Path)), path.Ext(entry.Path)
+		for n := 2; used[entry.Path]; n++ {
+			entry.Path = fmt.Sprintf("%s (%d)%s", base, n, ext)
+		}
+		used[entry.Path] = true
+	}
+
+	filename := fmt.Sprintf("documents_%s.zip", time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+
+	archive := zip.NewWriter(c.Writer)
+	for _, entry := range entries {
+		if err := writeBundleFile(archive, entry, docs[entry]); err != nil {
+			// Headers are already sent, so the client sees a truncated archive
+			c.Error(err)
+			return
+		}
+		c.Writer.Flush()
+	}
+
+	if err := writeBundleManifest(archive, entries); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := archive.Close(); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Writer.Flush()
+}
+
+// writeBundleFile copies one stored file into the archive, recording in the
+// entry whether it was left out or no longer matches its checksum. Only
+// errors writing the archive itself are returned.
+func writeBundleFile(archive *zip.Writer, entry *bundleEntry, doc *models.Document) error {
+	switch doc.ScanStatus {
+	case models.ScanClean:
+	case models.ScanInfected:
+		entry.Status = "quarantined"
+		return nil
+	default:
+		entry.Status = "not_scanned"
+		return nil
+	}
+
+	obj, err := storage.Store.Open(doc.StorageKey)
+	if err != nil {
+		entry.Status = "missing"
+		return nil
+	}
+	defer obj.Close()
+
+	w, err := archive.CreateHeader(&zip.FileHeader{
+		Name:     entry.Path,
+		Method:   zip.Deflate,
+		Modified: doc.UploadedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, hash), obj); err != nil {
+		return err
+	}
+	entry.Status = "included"
+	if entry.Checksum != "" && hex.EncodeToString(hash.Sum(nil)) != entry.Checksum {
+		entry.Status = "checksum_mismatch"
+	}
+	return nil
+}
+
+func writeBundleManifest(archive *zip.Writer, entries []*bundleEntry) error {
+	w, err := archive.Create("manifest.csv")
+	if err != nil {
+		return err
+	}
+	tw := utils.NewCSVTableWriter(w)
+	header := make([]string, len(bundleManifestColumns))
+	for i, col := range bundleManifestColumns {
+		header[i] = col.Name
+	}
+	if err := tw.WriteRow(header); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		row := make([]string, len(bundleManifestColumns))
+		for i, col := range bundleManifestColumns {
+			row[i] = col.Value(entry)
+		}
+		if err := tw.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	w, err = archive.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(gin.H{"generatedAt": time.Now(), "documents": entries})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"vendor-management/models"
+	"vendor-management/preview"
+	"vendor-management/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queuePreview starts generating the previews of a newly stored file once
+// the malware scanner has found it clean
+func queuePreview(version *models.DocumentVersion) {
+	if version.ScanStatus == models.ScanClean {
+		preview.Enqueue(version.StorageKey, version.ContentType)
+	}
+}
+
+// GetDocumentPreview serves a JPEG of the first page of the current version
+// of an image or PDF document, or a small thumbnail of it with
+// size=thumbnail. Previews are generated in the background after upload;
+// until they are ready the request is answered with 202 Accepted.
+func GetDocumentPreview(c *gin.Context) {
+	doc, exists := lookupDocument(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	size := preview.Size(c.DefaultQuery("size", string(preview.Page)))
+	if _, valid := preview.Sizes[size]; !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preview size, expected preview or thumbnail"})
+		return
+	}
+
+	version := &doc.Versions[doc.CurrentVersion-1]
+	if !preview.Supported(version.ContentType) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Previews are only available for images and PDFs"})
+		return
+	}
+	if !checkScanStatus(c, version) {
+		return
+	}
+
+	obj, err := storage.Store.Open(preview.Key(version.StorageKey, size))
+	if err == nil {
+		defer obj.Close()
+		c.Header("Content-Type", "image/jpeg")
+		c.Header("Cache-Control", "private, no-cache")
+		http.ServeContent(c.Writer, c.Request, "", obj.ModTime(), obj)
+		return
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read preview"})
+		return
+	}
+	if preview.Unsupported(version.StorageKey) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No preview can be generated for this document"})
+		return
+	}
+
+	// Files stored before previews were introduced, or whose generation was
+	// interrupted, are queued on first request
+	preview.Enqueue(version.StorageKey, version.ContentType)
+	c.Header("Retry-After", "2")
+	c.JSON(http.StatusAccepted, gin.H{"status": "pending"})
+}
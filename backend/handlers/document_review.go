@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+	"vendor-management/models"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RejectDocumentRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// UploadMyDocument lets a vendor upload one of their own documents. It is
+// held for review and does not count towards compliance until approved.
+func UploadMyDocument(c *gin.Context) {
+	if !limitUploadSize(c) {
+		return
+	}
+
+	// Suspended vendors may upload, as that is how they are reinstated
+	vendor, ok := currentVendorRecord(c, "Only vendors can upload their documents")
+	if !ok {
+		return
+	}
+
+	docType, err := parseDocumentType(c.PostForm("type"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	doc, ok := createDocument(c, vendor, "", docType, models.DocumentPending)
+	if !ok {
+		return
+	}
+	utils.NotifyAdmins("document_pending", doc.ID, fmt.Sprintf("%s uploaded %s for review", vendor.CompanyName, doc.Name))
+
+	c.JSON(http.StatusCreated, doc)
+}
+
+// currentVendor returns the vendor record of the logged-in vendor user,
+// writing the error response itself when there is none or the vendor is
+// suspended
+func currentVendor(c *gin.Context, forbidden string) (*models.Vendor, bool) {
+	vendor, ok := currentVendorRecord(c, forbidden)
+	if !ok || refuseSuspended(c, vendor) {
+		return nil, false
+	}
+	return vendor, true
+}
+
+// currentVendorRecord returns the vendor record of the logged-in vendor user
+// whatever its status, writing the error response itself when there is none
+func currentVendorRecord(c *gin.Context, forbidden string) (*models.Vendor, bool) {
+	user, ok := currentVendorUser(c, forbidden)
+	if !ok {
+		return nil, false
+	}
+	vendor, exists := activeVendorOf(user)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return nil, false
+	}
+	return vendor, true
+}
+
+// refuseSuspended writes 403 and reports true when the vendor is suspended
+func refuseSuspended(c *gin.Context, vendor *models.Vendor) bool {
+	if vendor.Status != "suspended" {
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Vendor is suspended until its required documents are in order"})
+	return true
+}
+
+// currentVendorUser returns the logged-in user when they have the vendor
+// role, writing the error response itself otherwise
+func currentVendorUser(c *gin.Context, forbidden string) (*models.User, bool) {
+	userID, _ := c.Get("userId")
+	user, exists := models.Users[userID.(string)]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, false
+	}
+	if user.Role != models.VendorRole {
+		c.JSON(http.StatusForbidden, gin.H{"error": forbidden})
+		return nil, false
+	}
+	return user, true
+}
+
+// activeVendorOf finds the vendor record of a user that is not in the trash
+func activeVendorOf(user *models.User) (*models.Vendor, bool) {
+	for _, vendor := range models.Vendors {
+		if vendor.UserID == user.ID && vendor.DeletedAt == nil {
+			return vendor, true
+		}
+	}
+	return nil, false
+}
+
+// DownloadMyDocument serves the file of one of the vendor's own documents.
+// Documents of other vendors and admin-only documents are reported as not
+// found so that their existence is not revealed.
+func DownloadMyDocument(c *gin.Context) {
+	vendor, ok := currentVendor(c, "Only vendors can download their documents")
+	if !ok {
+		return
+	}
+
+	doc, exists := lookupDocument(c.Param("id"))
+	if !exists || doc.VendorID != vendor.ID || doc.AdminOnly {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	serveStoredFile(c, &doc.Versions[doc.CurrentVersion-1], true)
+}
+
+// ListPendingDocuments is the admin review queue, oldest upload first
+func ListPendingDocuments(c *gin.Context) {
+	docs := make([]*models.Document, 0)
+	for _, doc := range models.Documents {
+		if doc.DeletedAt == nil && doc.Status == models.DocumentPending {
+			docs = append(docs, doc)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].UploadedAt.Before(docs[j].UploadedAt) })
+	c.JSON(http.StatusOK, docs)
+}
+
+// reviewDocument records an admin's decision on a pending document and tells
+// the vendor about it
+func reviewDocument(c *gin.Context, status models.DocumentStatus, reason string) {
+	doc, exists := lookupDocument(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+	if doc.Status != models.DocumentPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Document is not pending review"})
+		return
+	}
+
+	userID, _ := c.Get("userId")
+	now := time.Now()
+	doc.Status = status
+	doc.ReviewedBy = userID.(string)
+	doc.ReviewedAt = &now
+	doc.RejectionReason = reason
+	utils.SyncVendorDocument(doc)
+
+	if vendor, exists := models.Vendors[doc.VendorID]; exists {
+		if status == models.DocumentApproved {
+			recheckCompliance(vendor)
+		}
+		if vendor.UserID != "" {
+			message := fmt.Sprintf("Your document %s was approved", doc.Name)
+			if status == models.DocumentRejected {
+				message = fmt.Sprintf("Your document %s was rejected: %s", doc.Name, reason)
+			}
+			utils.Notify(vendor.UserID, "document_"+string(status), doc.ID, message)
+		}
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+func ApproveDocument(c *gin.Context) {
+	reviewDocument(c, models.DocumentApproved, "")
+}
+
+func RejectDocument(c *gin.Context) {
+	var req RejectDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reviewDocument(c, models.DocumentRejected, req.Reason)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"vendor-management/config"
+	"vendor-management/models"
+	"vendor-management/search"
+	"vendor-management/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// indexDocument extracts the text of the document's current file and adds it
+// to the search index together with its name, type and owner
+func indexDocument(doc *models.Document) {
+	metadata := []string{doc.Name, string(doc.Type)}
+	if vendor, exists := models.Vendors[doc.VendorID]; exists {
+		metadata = append(metadata, vendor.CompanyName)
+	}
+	if company, exists := models.Companies[doc.CompanyID]; exists {
+		metadata = append(metadata, company.LegalName)
+	}
+
+	text := ""
+	obj, err := storage.Store.Open(doc.StorageKey)
+	if err == nil {
+		var data []byte
+		data, err = io.ReadAll(io.LimitReader(obj, config.MaxUploadSize))
+		obj.Close()
+		if err == nil {
+			text, err = search.ExtractText(doc.ContentType, data)
+		}
+	}
+	if err != nil {
+		// The document stays findable by its metadata
+		log.Printf("Failed to extract text from document %s: %v", doc.ID, err)
+	}
+	search.Documents.Add(doc.ID, text, metadata...)
+}
+
+// searchLimit reads the limit query parameter, 20 by default and at most 100
+func searchLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		return 20
+	}
+	if limit > 100 {
+		return 100
+	}
+	return limit
+}
+
+// searchResults runs the q query parameter against the documents allowed
+// by the filter
+func searchResults(c *gin.Context, allowed func(*models.Document) bool) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter q is required"})
+		return
+	}
+
+	hits := search.Documents.Search(query, func(id string) bool {
+		doc, exists := lookupDocument(id)
+		return exists && allowed(doc)
+	}, searchLimit(c))
+
+	results := make([]gin.H, len(hits))
+	for i, hit := range hits {
+		results[i] = gin.H{"document": models.Documents[hit.DocumentID], "score": hit.Score, "snippet": hit.Snippet}
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// SearchDocuments searches the contents and metadata of every document,
+// narrowed by the same query parameters as ListDocuments
+func SearchDocuments(c *gin.Context) {
+	match, err := documentFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	searchResults(c, match)
+}
+
+// SearchMyDocuments searches the vendor's own documents, leaving out those
+// kept from the vendor
+func SearchMyDocuments(c *gin.Context) {
+	vendor, ok := currentVendor(c, "Only vendors can search their documents")
+	if !ok {
+		return
+	}
+	searchResults(c, func(doc *models.Document) bool {
+		return doc.VendorID == vendor.ID && !doc.AdminOnly
+	})
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+	"vendor-management/models"
+	"vendor-management/scanner"
+	"vendor-management/storage"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SetCurrentVersionRequest struct {
+	Version int `json:"version" binding:"required,min=1"`
+}
+
+// saveDocumentVersion stores the uploaded file and validity dates of a
+// multipart request as a new document version, returning the HTTP status to
+// use on failure
+func saveDocumentVersion(c *gin.Context, ownerID string, docType models.DocumentType, number int) (*models.DocumentVersion, int, error) {
+	validFrom, expiresAt, err := parseDocumentValidity(c.PostForm("validFrom"), c.PostForm("expiresAt"))
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	file, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, http.StatusRequestEntityTooLarge, errUploadTooLarge
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("File upload failed")
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("File upload failed")
+	}
+	defer src.Close()
+
+	contentType, status, err := validateUpload(docType, file, src)
+	if err != nil {
+		return nil, status, err
+	}
+
+	checksum, err := utils.FileChecksum(src)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("File upload failed")
+	}
+	if duplicate := findDuplicateDocument(ownerID, checksum); duplicate != nil {
+		return nil, http.StatusConflict, &duplicateDocumentError{documentID: duplicate.ID}
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, http.StatusBadRequest, errors.New("File upload failed")
+	}
+
+	userID, _ := c.Get("userId")
+	uploadedBy, _ := userID.(string)
+
+	// Files that cannot be scanned now are stored but not served until the
+	// rescan job has checked them
+	scanStatus := models.ScanClean
+	result, err := scanner.Default.Scan(src)
+	if err != nil {
+		log.Printf("Failed to scan upload %s: %v", file.Filename, err)
+		scanStatus = models.ScanPending
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, http.StatusBadRequest, errors.New("File upload failed")
+	}
+	if result != nil && result.Infected {
+		quarantined := &models.QuarantinedFile{
+			OwnerID:    ownerID,
+			Name:       file.Filename,
+			Signature:  result.Signature,
+			UploadedBy: uploadedBy,
+		}
+		if err := utils.QuarantineFile(quarantined, src, file.Size); err != nil {
+			log.Printf("Failed to quarantine upload %s: %v", file.Filename, err)
+		}
+		return nil, http.StatusUnprocessableEntity, fmt.Errorf("File is infected with %s and was quarantined", result.Signature)
+	}
+
+	// Generate unique storage key
+	key := fmt.Sprintf("documents/%s/%s%s", ownerID, generateID(), filepath.Ext(file.Filename))
+	if err := storage.Store.Put(key, src, file.Size); err != nil {
+		return nil, http.StatusInternalServerError, errors.New("Failed to save file")
+	}
+
+	return &models.DocumentVersion{
+		Version:     number,
+		Name:        file.Filename,
+		StorageKey:  key,
+		ContentType: contentType,
+		Size:        file.Size,
+		Checksum:    checksum,
+		ScanStatus:  scanStatus,
+		ValidFrom:   validFrom,
+		ExpiresAt:   expiresAt,
+		UploadedBy:  uploadedBy,
+		UploadedAt:  time.Now(),
+	}, 0, nil
+}
+
+// duplicateDocumentError reports an upload whose contents are already stored
+// for the same vendor or company
+type duplicateDocumentError struct {
+	documentID string
+}
+
+func (e *duplicateDocumentError) Error() string {
+	return "The same file is already stored as document " + e.documentID
+}
+
+// findDuplicateDocument returns the live document of the owner that has a
+// version with the given checksum, if any
+func findDuplicateDocument(ownerID, checksum string) *models.Document {
+	for _, doc := range models.Documents {
+		if doc.DeletedAt != nil || (doc.VendorID != ownerID && doc.CompanyID != ownerID) {
+			continue
+		}
+		for _, version := range doc.Versions {
+			if version.Checksum == checksum {
+				return doc
+			}
+		}
+	}
+	return nil
+}
+
+// uploadFailed reports an error from saveDocumentVersion, pointing at the
+// existing document when the upload is a duplicate
+func uploadFailed(c *gin.Context, status int, err error) {
+	var duplicate *duplicateDocumentError
+	if errors.As(err, &duplicate) {
+		c.JSON(status, gin.H{"error": err.Error(), "documentId": duplicate.documentID})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// applyCurrentVersion points the document at one of its versions
+func applyCurrentVersion(doc *models.Document, number int) {
+	version := doc.Versions[number-1]
+	doc.CurrentVersion = number
+	doc.Name = version.Name
+	doc.StorageKey = version.StorageKey
+	doc.ContentType = version.ContentType
+	doc.Size = version.Size
+	doc.Checksum = version.Checksum
+	doc.ScanStatus = version.ScanStatus
+	doc.ValidFrom = version.ValidFrom
+	doc.ExpiresAt = version.ExpiresAt
+	utils.SyncVendorDocument(doc)
+}
+
+// lookupDocumentVersion resolves the :version path parameter of a document
+func lookupDocumentVersion(doc *models.Document, param string) (*models.DocumentVersion, bool) {
+	number, err := strconv.Atoi(param)
+	if err != nil || number < 1 || number > len(doc.Versions) {
+		return nil, false
+	}
+	return &doc.Versions[number-1], true
+}
+
+// AddDocumentVersion uploads a replacement file for a document. The new
+// version becomes current and earlier files are kept.
+func AddDocumentVersion(c *gin.Context) {
+	doc, exists := lookupDocument(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if !limitUploadSize(c) {
+		return
+	}
+
+	ownerID := doc.VendorID
+	if ownerID == "" {
+		ownerID = doc.CompanyID
+	}
+	version, status, err := saveDocumentVersion(c, ownerID, doc.Type, len(doc.Versions)+1)
+	if err != nil {
+		uploadFailed(c, status, err)
+		return
+	}
+
+	doc.Versions = append(doc.Versions, *version)
+	applyCurrentVersion(doc, version.Version)
+	indexDocument(doc)
+	queuePreview(version)
+	if vendor, exists := models.Vendors[doc.VendorID]; exists {
+		recheckCompliance(vendor)
+	}
+
+	c.JSON(http.StatusCreated, doc)
+}
+
+func ListDocumentVersions(c *gin.Context) {
+	doc, exists := lookupDocument(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"currentVersion": doc.CurrentVersion,
+		"versions":       doc.Versions,
+	})
+}
+
+// GetDocumentVersion downloads the file of any version of a document
+func GetDocumentVersion(c *gin.Context) {
+	doc, exists := lookupDocument(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+	version, exists := lookupDocumentVersion(doc, c.Param("version"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document version not found"})
+		return
+	}
+
+	serveStoredFile(c, version, true)
+}
+
+// SetCurrentDocumentVersion moves the current pointer, for example to roll
+// back a replacement uploaded by mistake
+func SetCurrentDocumentVersion(c *gin.Context) {
+	doc, exists := lookupDocument(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	var req SetCurrentVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Version > len(doc.Versions) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Document version not found"})
+		return
+	}
+
+	applyCurrentVersion(doc, req.Version)
+	indexDocument(doc)
+	if vendor, exists := models.Vendors[doc.VendorID]; exists {
+		recheckCompliance(vendor)
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"vendor-management/models"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFlushEvery controls how many rows are written between flushes of the
+// response, so large exports reach the client incrementally
+const exportFlushEvery = 100
+
+type exportColumn[T any] struct {
+	Name  string
+	Value func(T) string
+}
+
+func formatExportDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+var vendorExportColumns = []exportColumn[*models.Vendor]{
+	{"id", func(v *models.Vendor) string { return v.ID }},
+	{"userId", func(v *models.Vendor) string { return v.UserID }},
+	{"companyId", func(v *models.Vendor) string { return v.CompanyID }},
+	{"companyName", func(v *models.Vendor) string { return v.CompanyName }},
+	{"type", func(v *models.Vendor) string { return v.Type }},
+	{"joiningDate", func(v *models.Vendor) string { return formatExportDate(v.JoiningDate) }},
+	{"endDate", func(v *models.Vendor) string { return formatExportDate(v.EndDate) }},
+	{"departmentId", func(v *models.Vendor) string { return v.DepartmentID }},
+	{"department", func(v *models.Vendor) string { return v.Department }},
+	{"projectId", func(v *models.Vendor) string { return v.ProjectID }},
+	{"projectName", func(v *models.Vendor) string { return v.ProjectName }},
+	{"status", func(v *models.Vendor) string { return v.Status }},
+}
+
+var assetExportColumns = []exportColumn[*models.Asset]{
+	{"id", func(a *models.Asset) string { return a.ID }},
+	{"name", func(a *models.Asset) string { return a.Name }},
+	{"type", func(a *models.Asset) string { return a.Type }},
+	{"serialNumber", func(a *models.Asset) string { return a.SerialNumber }},
+	{"assignedTo", func(a *models.Asset) string { return a.AssignedTo }},
+	{"assignedAt", func(a *models.Asset) string { return formatExportTime(a.AssignedAt) }},
+	{"returnedAt", func(a *models.Asset) string { return formatExportTime(a.ReturnedAt) }},
+	{"status", func(a *models.Asset) string { return a.Status }},
+}
+
+var documentExportColumns = []exportColumn[*models.Document]{
+	{"id", func(d *models.Document) string { return d.ID }},
+	{"vendorId", func(d *models.Document) string { return d.VendorID }},
+	{"companyId", func(d *models.Document) string { return d.CompanyID }},
+	{"name", func(d *models.Document) string { return d.Name }},
+	{"type", func(d *models.Document) string { return string(d.Type) }},
+	{"uploadedAt", func(d *models.Document) string { return formatExportTime(d.UploadedAt) }},
+	{"validFrom", func(d *models.Document) string { return formatExportDate(d.ValidFrom) }},
+	{"expiresAt", func(d *models.Document) string { return formatExportDate(d.ExpiresAt) }},
+	{"checksum", func(d *models.Document) string { return d.Checksum }},
+	{"status", func(d *models.Document) string { return string(d.Status) }},
+	{"scanStatus", func(d *models.Document) string { return string(d.ScanStatus) }},
+	{"adminOnly", func(d *models.Document) string { return strconv.FormatBool(d.AdminOnly) }},
+	{"legalHold", func(d *models.Document) string { return strconv.FormatBool(utils.UnderLegalHold(d)) }},
+}
+
+var attendanceExportColumns = []exportColumn[*models.Attendance]{
+	{"id", func(a *models.Attendance) string { return a.ID }},
+	{"vendorId", func(a *models.Attendance) string { return a.VendorID }},
+	{"date", func(a *models.Attendance) string { return formatExportDate(a.Date) }},
+	{"loginTime", func(a *models.Attendance) string { return formatExportTime(a.LoginTime) }},
+	{"logoutTime", func(a *models.Attendance) string { return formatExportTime(a.LogoutTime) }},
+	{"presentDay", func(a *models.Attendance) string {
+		return strconv.FormatFloat(float64(a.PresentDay), 'f', -1, 32)
+	}},
+	{"status", func(a *models.Attendance) string { return a.Status }},
+}
+
+// withCustomFieldColumns appends a cf.<key> column for every custom field
+// defined on the entity
+func withCustomFieldColumns[T any](columns []exportColumn[T], entity string, fields func(T) map[string]interface{}) []exportColumn[T] {
+	result := append([]exportColumn[T]{}, columns...)
+	for _, def := range customFieldsFor(entity) {
+		key := def.Key
+		result = append(result, exportColumn[T]{
+			Name:  customFieldQueryPrefix + key,
+			Value: func(item T) string { return formatCustomFieldValue(fields(item)[key]) },
+		})
+	}
+	return result
+}
+
+// selectExportColumns narrows the available columns to the comma-separated
+// list in the columns query parameter, preserving the requested order
+func selectExportColumns[T any](c *gin.Context, available []exportColumn[T]) ([]exportColumn[T], error) {
+	param := c.Query("columns")
+	if param == "" {
+		return available, nil
+	}
+
+	selected := make([]exportColumn[T], 0)
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, col := range available {
+			if col.Name == name {
+				selected = append(selected, col)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown column: %s", name)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("No columns selected")
+	}
+	return selected, nil
+}
+
+// streamExport writes the header row and every row produced by each to the
+// response in the format requested by the format query parameter (csv or
+// xlsx). Rows are flushed as they are produced rather than buffered; each
+// yields them sorted, so that repeated exports list rows in the same order.
+func streamExport[T any](c *gin.Context, name string, columns []exportColumn[T], each func(yield func(T) error) error) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export format"})
+		return
+	}
+
+	filename := fmt.Sprintf("%s_%s.%s", name, time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	var tw utils.TableWriter
+	if format == "xlsx" {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Status(http.StatusOK)
+		var err error
+		tw, err = utils.NewXLSXTableWriter(c.Writer, name)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		tw = utils.NewCSVTableWriter(c.Writer)
+	}
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	if err := tw.WriteRow(header); err != nil {
+		c.Error(err)
+		return
+	}
+
+	rows := 0
+	err := each(func(item T) error {
+		row := make([]string, len(columns))
+		for i, col := range columns {
+			row[i] = col.Value(item)
+		}
+		if err := tw.WriteRow(row); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery == 0 {
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Headers are already sent, so the client sees a truncated file
+		c.Error(err)
+		return
+	}
+
+	if err := tw.Close(); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Writer.Flush()
+}
+
+func ExportVendors(c *gin.Context) {
+	available := withCustomFieldColumns(vendorExportColumns, vendorEntity,
+		func(v *models.Vendor) map[string]interface{} { return v.CustomFields })
+	columns, err := selectExportColumns(c, available)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	match, err := vendorFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	streamExport(c, "vendors", columns, func(yield func(*models.Vendor) error) error {
+		items := make([]*models.Vendor, 0)
+		for _, vendor := range models.Vendors {
+			if match(vendor) {
+				items = append(items, vendor)
+			}
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+		for _, vendor := range items {
+			if err := yield(vendor); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func ExportAssets(c *gin.Context) {
+	available := withCustomFieldColumns(assetExportColumns, assetEntity,
+		func(a *models.Asset) map[string]interface{} { return a.CustomFields })
+	columns, err := selectExportColumns(c, available)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	match, err := assetFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	streamExport(c, "assets", columns, func(yield func(*models.Asset) error) error {
+		items := make([]*models.Asset, 0)
+		for _, asset := range models.Assets {
+			if match(asset) {
+				items = append(items, asset)
+			}
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+		for _, asset := range items {
+			if err := yield(asset); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func ExportDocuments(c *gin.Context) {
+	columns, err := selectExportColumns(c, documentExportColumns)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	match, err := documentFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	streamExport(c, "documents", columns, func(yield func(*models.Document) error) error {
+		items := make([]*models.Document, 0)
+		for _, doc := range models.Documents {
+			if match(doc) {
+				items = append(items, doc)
+			}
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+		for _, doc := range items {
+			if err := yield(doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func ExportAttendance(c *gin.Context) {
+	columns, err := selectExportColumns(c, attendanceExportColumns)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	match, err := attendanceFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	streamExport(c, "attendance", columns, func(yield func(*models.Attendance) error) error {
+		items := make([]*models.Attendance, 0)
+		for _, records := range models.AttendanceRecords {
+			for _, record := range records {
+				if match(record) {
+					items = append(items, record)
+				}
+			}
+		}
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].VendorID != items[j].VendorID {
+				return items[i].VendorID < items[j].VendorID
+			}
+			if !items[i].Date.Equal(items[j].Date) {
+				return items[i].Date.Before(items[j].Date)
+			}
+			return items[i].ID < items[j].ID
+		})
+		for _, record := range items {
+			if err := yield(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"vendor-management/models"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RunInvoicesRequest struct {
+	Month string `json:"month" binding:"required"` // YYYY-MM
+}
+
+type CreateTaxRuleRequest struct {
+	Name     string  `json:"name" binding:"required"`
+	Rate     float64 `json:"rate" binding:"required,gt=0,lte=100"`
+	Currency string  `json:"currency"`
+}
+
+// RunInvoices generates draft invoices for the requested month. Running it
+// again recalculates drafts, so attendance corrections can be picked up
+// before approval.
+func RunInvoices(c *gin.Context) {
+	var req RunInvoicesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	month, err := time.ParseInLocation("2006-01", req.Month, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month format"})
+		return
+	}
+
+	userID, _ := c.Get("userId")
+	c.JSON(http.StatusOK, utils.GenerateInvoices(month, userID.(string)))
+}
+
+func ListInvoices(c *gin.Context) {
+	vendorID := c.Query("vendorId")
+	status := c.Query("status")
+	month := c.Query("month")
+
+	invoices := make([]*models.Invoice, 0)
+	for _, invoice := range models.Invoices {
+		if vendorID != "" && invoice.VendorID != vendorID {
+			continue
+		}
+		if status != "" && string(invoice.Status) != status {
+			continue
+		}
+		if month != "" && invoice.PeriodStart.Format("2006-01") != month {
+			continue
+		}
+		invoices = append(invoices, invoice)
+	}
+	sort.Slice(invoices, func(i, j int) bool {
+		return invoices[i].Number < invoices[j].Number
+	})
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+func GetInvoice(c *gin.Context) {
+	invoice, exists := models.Invoices[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+func ApproveInvoice(c *gin.Context) {
+	invoice, exists := models.Invoices[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	}
+	if invoice.Status != models.InvoiceDraft {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only draft invoices can be approved"})
+		return
+	}
+
+	// Approval consumes the vendor's purchase order, when there is one
+	if po, exists := utils.MatchPurchaseOrder(invoice); exists {
+		if err := utils.ConsumePurchaseOrder(po, invoice); err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userID, _ := c.Get("userId")
+	now := time.Now()
+	invoice.Status = models.InvoiceApproved
+	invoice.ApprovedBy = userID.(string)
+	invoice.ApprovedAt = &now
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+func MarkInvoicePaid(c *gin.Context) {
+	invoice, exists := models.Invoices[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	}
+	if invoice.Status != models.InvoiceApproved {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only approved invoices can be marked as paid"})
+		return
+	}
+
+	now := time.Now()
+	invoice.Status = models.InvoicePaid
+	invoice.PaidAt = &now
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// invoiceLines lays out an invoice as fixed-width text for the PDF
+func invoiceLines(invoice *models.Invoice) []string {
+	billTo := invoice.VendorID
+	if vendor, exists := models.Vendors[invoice.VendorID]; exists {
+		billTo = vendor.CompanyName
+	}
+
+	lines := []string{
+		"INVOICE " + invoice.Number,
+		"",
+		"Bill to:  " + billTo,
+		"Vendor:   " + invoice.VendorID,
+		fmt.Sprintf("Period:   %s to %s", invoice.PeriodStart.Format("2006-01-02"), invoice.PeriodEnd.Format("2006-01-02")),
+		"Status:   " + string(invoice.Status),
+		"Currency: " + invoice.Currency,
+		"",
+		fmt.Sprintf("%-44s %10s %-5s %10s %12s", "Description", "Qty", "Unit", "Price", "Amount"),
+		strings.Repeat("-", 85),
+	}
+	for _, item := range invoice.LineItems {
+		lines = append(lines, fmt.Sprintf("%-44.44s %10.4g %-5s %10.2f %12.2f",
+			item.Description, item.Quantity, item.Unit, item.UnitPrice, item.Amount))
+	}
+	lines = append(lines, strings.Repeat("-", 85))
+	lines = append(lines, fmt.Sprintf("%72s %12.2f", "Subtotal", invoice.Subtotal))
+	for _, tax := range invoice.Taxes {
+		lines = append(lines, fmt.Sprintf("%72s %12.2f", fmt.Sprintf("%s (%g%%)", tax.Name, tax.Rate), tax.Amount))
+	}
+	lines = append(lines, fmt.Sprintf("%72s %12.2f", "Total "+invoice.Currency, invoice.Total))
+	return lines
+}
+
+func GetInvoicePDF(c *gin.Context) {
+	invoice, exists := models.Invoices[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, invoice.Number))
+	if err := utils.WriteTextPDF(c.Writer, invoiceLines(invoice)); err != nil {
+		c.Error(err)
+	}
+}
+
+func ListTaxRules(c *gin.Context) {
+	rules := make([]*models.TaxRule, 0, len(models.TaxRules))
+	for _, rule := range models.TaxRules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name < rules[j].Name
+	})
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func CreateTaxRule(c *gin.Context) {
+	var req CreateTaxRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	currency := strings.ToUpper(req.Currency)
+	if currency != "" && !currencyPattern.MatchString(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Currency must be a three-letter ISO 4217 code"})
+		return
+	}
+
+	rule := &models.TaxRule{
+		ID:       generateID(),
+		Name:     req.Name,
+		Rate:     req.Rate,
+		Currency: currency,
+	}
+	models.TaxRules[rule.ID] = rule
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func DeleteTaxRule(c *gin.Context) {
+	if _, exists := models.TaxRules[c.Param("id")]; !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tax rule not found"})
+		return
+	}
+
+	delete(models.TaxRules, c.Param("id"))
+	c.Status(http.StatusNoContent)
+}
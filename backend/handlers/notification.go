@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMyNotifications returns the current user's notifications, newest first.
+// Pass unread=true to only return unread ones.
+func GetMyNotifications(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications := make([]*models.Notification, 0)
+	for _, notification := range models.Notifications {
+		if notification.UserID != userID.(string) {
+			continue
+		}
+		if unreadOnly && notification.Read {
+			continue
+		}
+		notifications = append(notifications, notification)
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+func MarkNotificationRead(c *gin.Context) {
+	userID, _ := c.Get("userId")
+	notification, exists := models.Notifications[c.Param("id")]
+	if !exists || notification.UserID != userID.(string) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	notification.Read = true
+	c.JSON(http.StatusOK, notification)
+}
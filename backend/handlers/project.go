@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateProjectRequest struct {
+	Code         string  `json:"code" binding:"required"`
+	Name         string  `json:"name" binding:"required"`
+	DepartmentID string  `json:"departmentId"`
+	OwnerID      string  `json:"ownerId"`
+	Budget       float64 `json:"budget" binding:"min=0"`
+	StartDate    string  `json:"startDate" binding:"required"`
+	EndDate      string  `json:"endDate"`
+	Status       string  `json:"status" binding:"omitempty,oneof=planned active closed"`
+}
+
+// resolveProject links a vendor to a managed project, either by ID or by
+// matching the free-text name against project names and codes. Until any
+// projects are defined, unmatched names are kept as free text.
+func resolveProject(id, name string) (*models.Project, error) {
+	if id != "" {
+		project, exists := models.Projects[id]
+		if !exists {
+			return nil, errors.New("Project not found")
+		}
+		if name != "" && !namesEntity(name, project.Name, project.Code) {
+			return nil, errors.New("Project name does not match projectId")
+		}
+		return project, nil
+	}
+
+	for _, project := range models.Projects {
+		if namesEntity(name, project.Name, project.Code) {
+			return project, nil
+		}
+	}
+	if len(models.Projects) > 0 && name != "" {
+		return nil, errors.New("Unknown project: " + name)
+	}
+	return nil, nil
+}
+
+// trackProjectAssignment closes the vendor's current project assignment and
+// opens a new one when the vendor has moved to a different project
+func trackProjectAssignment(c *gin.Context, vendor *models.Vendor) {
+	assignments := models.ProjectAssignments[vendor.ID]
+	var current *models.ProjectAssignment
+	if n := len(assignments); n > 0 && assignments[n-1].EndedAt == nil {
+		current = assignments[n-1]
+	}
+
+	if current != nil && current.ProjectID == vendor.ProjectID {
+		return
+	}
+
+	now := time.Now()
+	if current != nil {
+		current.EndedAt = &now
+	}
+	if vendor.ProjectID == "" {
+		return
+	}
+
+	userID, _ := c.Get("userId")
+	assignedBy, _ := userID.(string)
+	models.ProjectAssignments[vendor.ID] = append(assignments, &models.ProjectAssignment{
+		ID:         generateID(),
+		VendorID:   vendor.ID,
+		ProjectID:  vendor.ProjectID,
+		AssignedBy: assignedBy,
+		StartedAt:  now,
+	})
+}
+
+// applyProjectRequest validates the request and copies it onto the project,
+// returning the HTTP status to use on failure
+func applyProjectRequest(project *models.Project, req *CreateProjectRequest) (int, error) {
+	for _, other := range models.Projects {
+		if other.ID != project.ID && strings.EqualFold(other.Code, req.Code) {
+			return http.StatusConflict, errors.New("Project code already exists")
+		}
+	}
+	if req.DepartmentID != "" {
+		if _, exists := models.Departments[req.DepartmentID]; !exists {
+			return http.StatusBadRequest, errors.New("Department not found")
+		}
+	}
+	if err := validateOwner(req.OwnerID); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return http.StatusBadRequest, errors.New("Invalid start date format")
+	}
+	var endDate time.Time
+	if req.EndDate != "" {
+		endDate, err = time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return http.StatusBadRequest, errors.New("Invalid end date format")
+		}
+		if endDate.Before(startDate) {
+			return http.StatusBadRequest, errors.New("End date must not be before start date")
+		}
+	}
+
+	project.Code = req.Code
+	project.Name = req.Name
+	project.DepartmentID = req.DepartmentID
+	project.OwnerID = req.OwnerID
+	project.Budget = req.Budget
+	project.StartDate = startDate
+	project.EndDate = endDate
+	if req.Status != "" {
+		project.Status = req.Status
+	}
+	return 0, nil
+}
+
+func CreateProject(c *gin.Context) {
+	var req CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project := &models.Project{
+		ID:        generateID(),
+		Status:    "active",
+		CreatedAt: time.Now(),
+		Version:   1,
+	}
+	if status, err := applyProjectRequest(project, &req); err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	models.Projects[project.ID] = project
+	setETag(c, project.Version)
+	c.JSON(http.StatusCreated, project)
+}
+
+func ListProjects(c *gin.Context) {
+	departmentID := c.Query("departmentId")
+	status := c.Query("status")
+	projects := make([]*models.Project, 0)
+	for _, project := range models.Projects {
+		if departmentID != "" && project.DepartmentID != departmentID {
+			continue
+		}
+		if status != "" && project.Status != status {
+			continue
+		}
+		projects = append(projects, project)
+	}
+	c.JSON(http.StatusOK, projects)
+}
+
+func GetProject(c *gin.Context) {
+	project, exists := models.Projects[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	setETag(c, project.Version)
+	c.JSON(http.StatusOK, project)
+}
+
+func UpdateProject(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	project, exists := models.Projects[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	var req CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !checkIfMatch(c, project.Version) {
+		return
+	}
+
+	if status, err := applyProjectRequest(project, &req); err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	project.Version++
+
+	// Keep the project name mirrored on assigned vendors
+	for _, vendor := range models.Vendors {
+		if vendor.ProjectID == project.ID && vendor.ProjectName != project.Name {
+			vendor.ProjectName = project.Name
+			vendor.Version++
+			recordVendorRevision(c, vendor)
+		}
+	}
+
+	setETag(c, project.Version)
+	c.JSON(http.StatusOK, project)
+}
+
+// GetProjectRoster lists the vendors on a project, either currently or at
+// the time given by the optional asOf query parameter
+func GetProjectRoster(c *gin.Context) {
+	project, exists := models.Projects[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	asOf := time.Now()
+	if value := c.Query("asOf"); value != "" {
+		var err error
+		asOf, err = parseAsOf(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid asOf format"})
+			return
+		}
+	}
+
+	roster := make([]gin.H, 0)
+	for vendorID, assignments := range models.ProjectAssignments {
+		vendor, exists := lookupVendor(vendorID)
+		if !exists {
+			continue
+		}
+		for _, assignment := range assignments {
+			if assignment.ProjectID != project.ID || assignment.StartedAt.After(asOf) {
+				continue
+			}
+			if assignment.EndedAt != nil && !assignment.EndedAt.After(asOf) {
+				continue
+			}
+			roster = append(roster, gin.H{
+				"vendor":     vendor,
+				"assignment": assignment,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project": project,
+		"asOf":    asOf,
+		"roster":  roster,
+	})
+}
+
+// GetVendorAssignments returns the vendor's project reassignment history
+func GetVendorAssignments(c *gin.Context) {
+	id := c.Param("id")
+	if _, exists := models.Vendors[id]; !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	assignments := models.ProjectAssignments[id]
+	if assignments == nil {
+		assignments = make([]*models.ProjectAssignment, 0)
+	}
+	c.JSON(http.StatusOK, assignments)
+}
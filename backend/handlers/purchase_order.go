@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"vendor-management/models"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreatePurchaseOrderRequest struct {
+	Number    string  `json:"number" binding:"required"`
+	VendorID  string  `json:"vendorId" binding:"required"`
+	ProjectID string  `json:"projectId"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"`
+	Currency  string  `json:"currency" binding:"required"`
+	StartDate string  `json:"startDate" binding:"required"`
+	EndDate   string  `json:"endDate"`
+}
+
+type UpdatePurchaseOrderRequest struct {
+	Amount  float64 `json:"amount" binding:"required,gt=0"`
+	EndDate string  `json:"endDate"`
+	Status  string  `json:"status" binding:"required,oneof=open closed"`
+}
+
+// purchaseOrderView adds the derived utilization figures to a purchase order
+func purchaseOrderView(po *models.PurchaseOrder) gin.H {
+	return gin.H{
+		"purchaseOrder": po,
+		"remaining":     utils.RoundMoney(po.Amount - po.Consumed),
+		"utilization":   utils.RoundMoney(utils.PurchaseOrderUtilization(po)),
+	}
+}
+
+// parsePurchaseOrderEndDate parses an optional end date that must not fall
+// before the start date
+func parsePurchaseOrderEndDate(value string, startDate time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	endDate, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New("Invalid end date format")
+	}
+	if endDate.Before(startDate) {
+		return time.Time{}, errors.New("End date must not be before start date")
+	}
+	return endDate, nil
+}
+
+func CreatePurchaseOrder(c *gin.Context) {
+	var req CreatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, other := range models.PurchaseOrders {
+		if strings.EqualFold(other.Number, req.Number) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Purchase order number already exists"})
+			return
+		}
+	}
+	if _, exists := lookupVendor(req.VendorID); !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Vendor not found"})
+		return
+	}
+	if req.ProjectID != "" {
+		if _, exists := models.Projects[req.ProjectID]; !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Project not found"})
+			return
+		}
+	}
+	currency := strings.ToUpper(req.Currency)
+	if !currencyPattern.MatchString(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Currency must be a three-letter ISO 4217 code"})
+		return
+	}
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format"})
+		return
+	}
+	endDate, err := parsePurchaseOrderEndDate(req.EndDate, startDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("userId")
+	po := &models.PurchaseOrder{
+		ID:           generateID(),
+		Number:       req.Number,
+		VendorID:     req.VendorID,
+		ProjectID:    req.ProjectID,
+		Amount:       req.Amount,
+		Currency:     currency,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Status:       models.PurchaseOrderOpen,
+		Consumptions: make([]models.PurchaseOrderConsumption, 0),
+		CreatedBy:    userID.(string),
+		CreatedAt:    time.Now(),
+		Version:      1,
+	}
+	models.PurchaseOrders[po.ID] = po
+
+	setETag(c, po.Version)
+	c.JSON(http.StatusCreated, purchaseOrderView(po))
+}
+
+func ListPurchaseOrders(c *gin.Context) {
+	vendorID := c.Query("vendorId")
+	projectID := c.Query("projectId")
+	status := c.Query("status")
+
+	orders := make([]*models.PurchaseOrder, 0)
+	for _, po := range models.PurchaseOrders {
+		if vendorID != "" && po.VendorID != vendorID {
+			continue
+		}
+		if projectID != "" && po.ProjectID != projectID {
+			continue
+		}
+		if status != "" && string(po.Status) != status {
+			continue
+		}
+		orders = append(orders, po)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].Number < orders[j].Number
+	})
+	views := make([]gin.H, 0, len(orders))
+	for _, po := range orders {
+		views = append(views, purchaseOrderView(po))
+	}
+
+	c.JSON(http.StatusOK, views)
+}
+
+func GetPurchaseOrder(c *gin.Context) {
+	po, exists := models.PurchaseOrders[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Purchase order not found"})
+		return
+	}
+
+	setETag(c, po.Version)
+	c.JSON(http.StatusOK, purchaseOrderView(po))
+}
+
+// UpdatePurchaseOrder changes the ceiling, end date or status. The ceiling
+// cannot drop below what has already been consumed.
+func UpdatePurchaseOrder(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	po, exists := models.PurchaseOrders[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Purchase order not found"})
+		return
+	}
+
+	var req UpdatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !checkIfMatch(c, po.Version) {
+		return
+	}
+
+	if req.Amount < po.Consumed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount cannot be less than the consumed amount"})
+		return
+	}
+	endDate, err := parsePurchaseOrderEndDate(req.EndDate, po.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	po.Amount = req.Amount
+	po.EndDate = endDate
+	po.Status = models.PurchaseOrderStatus(req.Status)
+	po.Version++
+
+	// A raised ceiling re-arms the alerts for thresholds no longer crossed,
+	// a lowered one may cross new thresholds
+	if crossed := utils.CrossedThreshold(po); crossed < po.AlertedThreshold {
+		po.AlertedThreshold = crossed
+	}
+	utils.CheckPurchaseOrderThresholds(po)
+
+	setETag(c, po.Version)
+	c.JSON(http.StatusOK, purchaseOrderView(po))
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"vendor-management/models"
+	"vendor-management/storage"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListQuarantine returns the files flagged by the malware scanner, newest
+// first
+func ListQuarantine(c *gin.Context) {
+	files := make([]*models.QuarantinedFile, 0, len(models.QuarantinedFiles))
+	for _, file := range models.QuarantinedFiles {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].QuarantinedAt.After(files[j].QuarantinedAt) })
+	c.JSON(http.StatusOK, files)
+}
+
+// RunRescan scans pending files now rather than waiting for the next run of
+// the rescan job
+func RunRescan(c *gin.Context) {
+	utils.RescanDocuments()
+	ListQuarantine(c)
+}
+
+// DeleteQuarantinedFile destroys a quarantined file once it has been dealt
+// with. Documents it belonged to keep the version, marked infected.
+func DeleteQuarantinedFile(c *gin.Context) {
+	file, exists := models.QuarantinedFiles[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quarantined file not found"})
+		return
+	}
+
+	if doc, exists := models.Documents[file.DocumentID]; exists && utils.UnderLegalHold(doc) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Document is under legal hold"})
+		return
+	}
+
+	if err := storage.Store.Delete(file.StorageKey); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete file"})
+		return
+	}
+	delete(models.QuarantinedFiles, file.ID)
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"vendor-management/models"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type CreateRateCardRequest struct {
+	Unit               string  `json:"unit" binding:"required,oneof=hourly daily monthly"`
+	Rate               float64 `json:"rate" binding:"required,gt=0"`
+	Currency           string  `json:"currency" binding:"required"`
+	OvertimeMultiplier float64 `json:"overtimeMultiplier" binding:"omitempty,gte=1"`
+	EffectiveFrom      string  `json:"effectiveFrom" binding:"required"`
+	EffectiveTo        string  `json:"effectiveTo"`
+}
+
+// addRateCard validates the request and appends a new rate card version for
+// the owner. A new card must start after the previous one, which is closed
+// the day before if it was open-ended.
+func addRateCard(c *gin.Context, ownerID string, card *models.RateCard) error {
+	var req CreateRateCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return err
+	}
+
+	currency := strings.ToUpper(req.Currency)
+	if !currencyPattern.MatchString(currency) {
+		return errors.New("Currency must be a three-letter ISO 4217 code")
+	}
+	effectiveFrom, err := time.Parse("2006-01-02", req.EffectiveFrom)
+	if err != nil {
+		return errors.New("Invalid effective from date format")
+	}
+	var effectiveTo time.Time
+	if req.EffectiveTo != "" {
+		effectiveTo, err = time.Parse("2006-01-02", req.EffectiveTo)
+		if err != nil {
+			return errors.New("Invalid effective to date format")
+		}
+		if effectiveTo.Before(effectiveFrom) {
+			return errors.New("Effective to date must not be before effective from date")
+		}
+	}
+
+	cards := models.RateCards[ownerID]
+	if n := len(cards); n > 0 {
+		previous := cards[n-1]
+		if !effectiveFrom.After(previous.EffectiveFrom) {
+			return errors.New("Rate card must start after the current rate card")
+		}
+		if !previous.EffectiveTo.IsZero() && !effectiveFrom.After(previous.EffectiveTo) {
+			return errors.New("Rate card overlaps the current rate card")
+		}
+		if previous.EffectiveTo.IsZero() {
+			previous.EffectiveTo = effectiveFrom.AddDate(0, 0, -1)
+		}
+	}
+
+	multiplier := req.OvertimeMultiplier
+	if multiplier == 0 {
+		multiplier = 1
+	}
+	userID, _ := c.Get("userId")
+
+	card.ID = generateID()
+	card.Version = len(cards) + 1
+	card.Unit = req.Unit
+	card.Rate = req.Rate
+	card.Currency = currency
+	card.OvertimeMultiplier = multiplier
+	card.EffectiveFrom = effectiveFrom
+	card.EffectiveTo = effectiveTo
+	card.CreatedBy = userID.(string)
+	card.CreatedAt = time.Now()
+
+	models.RateCards[ownerID] = append(cards, card)
+	return nil
+}
+
+// listRateCards returns every rate card version of the owner, or with the
+// date query parameter the one in effect on that date
+func listRateCards(c *gin.Context, cards []*models.RateCard, lookup func(time.Time) (*models.RateCard, bool)) {
+	if value := c.Query("date"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+			return
+		}
+		card, ok := lookup(date)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No rate card in effect on that date"})
+			return
+		}
+		c.JSON(http.StatusOK, card)
+		return
+	}
+
+	if cards == nil {
+		cards = make([]*models.RateCard, 0)
+	}
+	c.JSON(http.StatusOK, cards)
+}
+
+func ListVendorRates(c *gin.Context) {
+	vendor, exists := lookupVendor(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	listRateCards(c, models.RateCards[vendor.ID], func(date time.Time) (*models.RateCard, bool) {
+		return utils.EffectiveRateCard(vendor, date)
+	})
+}
+
+func CreateVendorRate(c *gin.Context) {
+	vendor, exists := lookupVendor(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	card := &models.RateCard{VendorID: vendor.ID}
+	if err := addRateCard(c, vendor.ID, card); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, card)
+}
+
+func ListCompanyRates(c *gin.Context) {
+	company, exists := lookupCompany(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
+		return
+	}
+
+	cards := models.RateCards[company.ID]
+	listRateCards(c, cards, func(date time.Time) (*models.RateCard, bool) {
+		for _, card := range cards {
+			if utils.RateCardCovers(card, date) {
+				return card, true
+			}
+		}
+		return nil, false
+	})
+}
+
+func CreateCompanyRate(c *gin.Context) {
+	company, exists := lookupCompany(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
+		return
+	}
+
+	card := &models.RateCard{CompanyID: company.ID}
+	if err := addRateCard(c, company.ID, card); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, card)
+}
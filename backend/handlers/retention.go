@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"time"
+	"vendor-management/models"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateRetentionRuleRequest struct {
+	DocumentType string `json:"documentType" binding:"required"`
+	Years        int    `json:"years" binding:"min=0"`
+	Months       int    `json:"months" binding:"min=0"`
+}
+
+type LegalHoldRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+func ListRetentionRules(c *gin.Context) {
+	rules := make([]*models.RetentionRule, 0, len(models.RetentionRules))
+	for _, rule := range models.RetentionRules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].DocumentType < rules[j].DocumentType
+	})
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func CreateRetentionRule(c *gin.Context) {
+	var req CreateRetentionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	docType, err := parseDocumentType(req.DocumentType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Years == 0 && req.Months == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Retention period must be at least one month"})
+		return
+	}
+
+	for _, rule := range models.RetentionRules {
+		if rule.DocumentType == docType {
+			c.JSON(http.StatusConflict, gin.H{"error": "A retention rule for this document type already exists"})
+			return
+		}
+	}
+
+	userID, _ := c.Get("userId")
+	rule := &models.RetentionRule{
+		ID:           generateID(),
+		DocumentType: docType,
+		Years:        req.Years,
+		Months:       req.Months,
+		CreatedAt:    time.Now(),
+	}
+	rule.CreatedBy, _ = userID.(string)
+	models.RetentionRules[rule.ID] = rule
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func DeleteRetentionRule(c *gin.Context) {
+	if _, exists := models.RetentionRules[c.Param("id")]; !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Retention rule not found"})
+		return
+	}
+
+	delete(models.RetentionRules, c.Param("id"))
+	c.Status(http.StatusNoContent)
+}
+
+// ListDisposalCertificates returns the certificates of disposed documents,
+// most recent first, optionally for one vendor
+func ListDisposalCertificates(c *gin.Context) {
+	vendorID := c.Query("vendorId")
+
+	certificates := make([]*models.DisposalCertificate, 0)
+	for _, certificate := range models.DisposalCertificates {
+		if vendorID == "" || certificate.VendorID == vendorID {
+			certificates = append(certificates, certificate)
+		}
+	}
+	sort.Slice(certificates, func(i, j int) bool {
+		if !certificates[i].DisposedAt.Equal(certificates[j].DisposedAt) {
+			return certificates[i].DisposedAt.After(certificates[j].DisposedAt)
+		}
+		return certificates[i].DocumentID < certificates[j].DocumentID
+	})
+
+	c.JSON(http.StatusOK, certificates)
+}
+
+func GetDisposalCertificate(c *gin.Context) {
+	certificate, exists := models.DisposalCertificates[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Disposal certificate not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, certificate)
+}
+
+// RunDisposal runs the nightly disposal job immediately and returns the
+// certificates it issued
+func RunDisposal(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.DisposeExpiredDocuments())
+}
+
+// newLegalHold binds a legal hold request placed by the current user,
+// reporting whether it was valid
+func newLegalHold(c *gin.Context) (*models.LegalHold, bool) {
+	var req LegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	userID, _ := c.Get("userId")
+	hold := &models.LegalHold{Reason: req.Reason, PlacedAt: time.Now()}
+	hold.PlacedBy, _ = userID.(string)
+	return hold, true
+}
+
+// PlaceVendorLegalHold blocks deletion of a vendor and all of its documents.
+// Vendors in the trash can be held too, which keeps them from being purged.
+func PlaceVendorLegalHold(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	vendor, exists := models.Vendors[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	hold, ok := newLegalHold(c)
+	if !ok {
+		return
+	}
+	vendor.LegalHold = hold
+	vendor.Version++
+	recordVendorRevision(c, vendor)
+
+	c.JSON(http.StatusOK, vendor)
+}
+
+func ReleaseVendorLegalHold(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	vendor, exists := models.Vendors[c.Param("id")]
+	if !exists || vendor.LegalHold == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor is not under legal hold"})
+		return
+	}
+
+	vendor.LegalHold = nil
+	vendor.Version++
+	recordVendorRevision(c, vendor)
+
+	c.JSON(http.StatusOK, vendor)
+}
+
+// PlaceDocumentLegalHold blocks deletion of a document. Documents in the
+// trash can be held too, which keeps them from being purged.
+func PlaceDocumentLegalHold(c *gin.Context) {
+	doc, exists := models.Documents[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	hold, ok := newLegalHold(c)
+	if !ok {
+		return
+	}
+	doc.LegalHold = hold
+	utils.SyncVendorDocument(doc)
+
+	c.JSON(http.StatusOK, doc)
+}
+
+func ReleaseDocumentLegalHold(c *gin.Context) {
+	doc, exists := models.Documents[c.Param("id")]
+	if !exists || doc.LegalHold == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document is not under legal hold"})
+		return
+	}
+
+	doc.LegalHold = nil
+	utils.SyncVendorDocument(doc)
+
+	c.JSON(http.StatusOK, doc)
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	minReviewScore = 1
+	maxReviewScore = 5
+)
+
+type CreateReviewCriterionRequest struct {
+	Key    string  `json:"key" binding:"required"`
+	Name   string  `json:"name" binding:"required"`
+	Weight float64 `json:"weight" binding:"required,gt=0"`
+}
+
+type CreateReviewRequest struct {
+	PeriodStart string             `json:"periodStart" binding:"required"`
+	PeriodEnd   string             `json:"periodEnd" binding:"required"`
+	Scores      map[string]float64 `json:"scores" binding:"required"`
+	Comments    string             `json:"comments"`
+}
+
+// VendorRating aggregates a vendor's performance reviews
+type VendorRating struct {
+	Average        float64            `json:"average"`
+	Count          int                `json:"count"`
+	ByCriterion    map[string]float64 `json:"byCriterion"`
+	LastReviewedAt *time.Time         `json:"lastReviewedAt,omitempty"`
+}
+
+func ListReviewCriteria(c *gin.Context) {
+	criteria := make([]*models.ReviewCriterion, 0)
+	for _, criterion := range models.ReviewCriteria {
+		criteria = append(criteria, criterion)
+	}
+	sort.Slice(criteria, func(i, j int) bool { return criteria[i].Key < criteria[j].Key })
+	c.JSON(http.StatusOK, criteria)
+}
+
+func CreateReviewCriterion(c *gin.Context) {
+	var req CreateReviewCriterionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !customFieldKeyPattern.MatchString(req.Key) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Key must start with a letter and contain only letters, digits and underscores"})
+		return
+	}
+	for _, criterion := range models.ReviewCriteria {
+		if criterion.Key == req.Key {
+			c.JSON(http.StatusConflict, gin.H{"error": "Review criterion already exists"})
+			return
+		}
+	}
+
+	criterion := &models.ReviewCriterion{
+		ID:     generateID(),
+		Key:    req.Key,
+		Name:   req.Name,
+		Weight: req.Weight,
+	}
+	models.ReviewCriteria[criterion.ID] = criterion
+	c.JSON(http.StatusCreated, criterion)
+}
+
+// DeleteReviewCriterion stops the criterion being scored on new reviews.
+// Existing reviews keep their recorded scores.
+func DeleteReviewCriterion(c *gin.Context) {
+	id := c.Param("id")
+	if _, exists := models.ReviewCriteria[id]; !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review criterion not found"})
+		return
+	}
+	if len(models.ReviewCriteria) == 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one review criterion is required"})
+		return
+	}
+
+	delete(models.ReviewCriteria, id)
+	c.Status(http.StatusNoContent)
+}
+
+// weightedScore checks that every criterion is scored within range and
+// returns the weighted average
+func weightedScore(scores map[string]float64) (float64, error) {
+	known := make(map[string]bool)
+	var total, weights float64
+	for _, criterion := range models.ReviewCriteria {
+		known[criterion.Key] = true
+		score, ok := scores[criterion.Key]
+		if !ok {
+			return 0, fmt.Errorf("Missing score for %s", criterion.Key)
+		}
+		if score < minReviewScore || score > maxReviewScore {
+			return 0, fmt.Errorf("Score for %s must be between %d and %d", criterion.Key, minReviewScore, maxReviewScore)
+		}
+		total += score * criterion.Weight
+		weights += criterion.Weight
+	}
+	for key := range scores {
+		if !known[key] {
+			return 0, fmt.Errorf("Unknown review criterion: %s", key)
+		}
+	}
+	return roundScore(total / weights), nil
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
+
+func CreateReview(c *gin.Context) {
+	vendor, exists := lookupVendor(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	var req CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	periodStart, err := time.Parse("2006-01-02", req.PeriodStart)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period start format"})
+		return
+	}
+	periodEnd, err := time.Parse("2006-01-02", req.PeriodEnd)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid period end format"})
+		return
+	}
+	if periodEnd.Before(periodStart) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Period end must not be before period start"})
+		return
+	}
+
+	overall, err := weightedScore(req.Scores)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("userId")
+	review := &models.PerformanceReview{
+		ID:           generateID(),
+		VendorID:     vendor.ID,
+		ReviewerID:   userID.(string),
+		PeriodStart:  periodStart,
+		PeriodEnd:    periodEnd,
+		Scores:       req.Scores,
+		OverallScore: overall,
+		Comments:     req.Comments,
+		CreatedAt:    time.Now(),
+	}
+	models.PerformanceReviews[vendor.ID] = append(models.PerformanceReviews[vendor.ID], review)
+
+	c.JSON(http.StatusCreated, review)
+}
+
+func ListVendorReviews(c *gin.Context) {
+	id := c.Param("id")
+	if _, exists := models.Vendors[id]; !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	reviews := models.PerformanceReviews[id]
+	if reviews == nil {
+		reviews = make([]*models.PerformanceReview, 0)
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+// vendorRating aggregates all reviews of a vendor
+func vendorRating(vendorID string) VendorRating {
+	rating := VendorRating{ByCriterion: make(map[string]float64)}
+	reviews := models.PerformanceReviews[vendorID]
+	if len(reviews) == 0 {
+		return rating
+	}
+
+	var total float64
+	criterionTotals := make(map[string]float64)
+	criterionCounts := make(map[string]int)
+	for _, review := range reviews {
+		total += review.OverallScore
+		for key, score := range review.Scores {
+			criterionTotals[key] += score
+			criterionCounts[key]++
+		}
+		if rating.LastReviewedAt == nil || review.PeriodEnd.After(*rating.LastReviewedAt) {
+			periodEnd := review.PeriodEnd
+			rating.LastReviewedAt = &periodEnd
+		}
+	}
+
+	rating.Count = len(reviews)
+	rating.Average = roundScore(total / float64(len(reviews)))
+	for key, sum := range criterionTotals {
+		rating.ByCriterion[key] = roundScore(sum / float64(criterionCounts[key]))
+	}
+	return rating
+}
+
+type leaderboardEntry struct {
+	VendorID    string  `json:"vendorId"`
+	CompanyName string  `json:"companyName"`
+	ProjectName string  `json:"projectName"`
+	Average     float64 `json:"average"`
+	Count       int     `json:"count"`
+}
+
+// GetReviewLeaderboard ranks reviewed vendors by average rating within each
+// department. The optional department or departmentId query parameters
+// restrict the report to one department and limit caps each ranking.
+func GetReviewLeaderboard(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+	}
+
+	match, err := vendorFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	byDepartment := make(map[string][]leaderboardEntry)
+	for _, vendor := range models.Vendors {
+		if !match(vendor) {
+			continue
+		}
+		rating := vendorRating(vendor.ID)
+		if rating.Count == 0 {
+			continue
+		}
+		byDepartment[vendor.Department] = append(byDepartment[vendor.Department], leaderboardEntry{
+			VendorID:    vendor.ID,
+			CompanyName: vendor.CompanyName,
+			ProjectName: vendor.ProjectName,
+			Average:     rating.Average,
+			Count:       rating.Count,
+		})
+	}
+
+	departments := make([]string, 0, len(byDepartment))
+	for department := range byDepartment {
+		departments = append(departments, department)
+	}
+	sort.Strings(departments)
+
+	report := make([]gin.H, 0)
+	for _, department := range departments {
+		entries := byDepartment[department]
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].Average != entries[j].Average {
+				return entries[i].Average > entries[j].Average
+			}
+			return entries[i].Count > entries[j].Count
+		})
+		var sum float64
+		for _, entry := range entries {
+			sum += entry.Average
+		}
+		departmentAverage := roundScore(sum / float64(len(entries)))
+		if limit > 0 && len(entries) > limit {
+			entries = entries[:limit]
+		}
+		report = append(report, gin.H{
+			"department": department,
+			"average":    departmentAverage,
+			"vendors":    entries,
+		})
+	}
+
+	c.JSON(http.StatusOK, report)
+}
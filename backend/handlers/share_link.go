@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"vendor-management/config"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateShareLinkRequest struct {
+	ExpiresInHours int  `json:"expiresInHours" binding:"omitempty,min=1"`
+	MaxDownloads   int  `json:"maxDownloads" binding:"omitempty,min=1"`
+	SingleUse      bool `json:"singleUse"`
+}
+
+// shareLinkMu guards the download counts and audit trails of share links,
+// which unauthenticated requests update in parallel
+var shareLinkMu sync.Mutex
+
+// signShareLink computes the signature carried by a share link URL
+func signShareLink(linkID string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(config.ShareLinkSecret))
+	fmt.Fprintf(mac, "%s.%d", linkID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// shareLinkURL builds the absolute URL handed out for a link
+func shareLinkURL(c *gin.Context, link *models.ShareLink) string {
+	base := strings.TrimSuffix(config.PublicBaseURL, "/")
+	if base == "" {
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+		base = scheme + "://" + c.Request.Host
+	}
+	expires := link.ExpiresAt.Unix()
+	query := url.Values{
+		"expires":   {strconv.FormatInt(expires, 10)},
+		"signature": {signShareLink(link.ID, expires)},
+	}
+	return base + "/api/shared/documents/" + link.ID + "?" + query.Encode()
+}
+
+// CreateShareLink hands out a signed URL for the current version of a
+// document that works without logging in
+func CreateShareLink(c *gin.Context) {
+	doc, exists := lookupDocument(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	var req CreateShareLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	age := config.ShareLinkDefaultAge
+	if req.ExpiresInHours > 0 {
+		age = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	if age > config.ShareLinkMaxAge {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Share links can last at most %d hours", int(config.ShareLinkMaxAge.Hours()))})
+		return
+	}
+	maxDownloads := req.MaxDownloads
+	if req.SingleUse {
+		maxDownloads = 1
+	}
+
+	userID, _ := c.Get("userId")
+	now := time.Now()
+	link := &models.ShareLink{
+		ID:           generateID(),
+		DocumentID:   doc.ID,
+		Version:      doc.CurrentVersion,
+		ExpiresAt:    now.Add(age).Truncate(time.Second),
+		MaxDownloads: maxDownloads,
+		CreatedBy:    userID.(string),
+		CreatedAt:    now,
+	}
+	models.ShareLinks[link.ID] = link
+
+	c.JSON(http.StatusCreated, gin.H{"link": link, "url": shareLinkURL(c, link)})
+}
+
+// ListShareLinks returns the share links of a document with the audit trail
+// of every request made for them
+func ListShareLinks(c *gin.Context) {
+	doc, exists := lookupDocument(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	shareLinkMu.Lock()
+	defer shareLinkMu.Unlock()
+	links := make([]gin.H, 0)
+	for _, link := range models.ShareLinks {
+		if link.DocumentID != doc.ID {
+			continue
+		}
+		accesses := models.ShareLinkAccesses[link.ID]
+		if accesses == nil {
+			accesses = make([]*models.ShareLinkAccess, 0)
+		}
+		links = append(links, gin.H{"link": link, "accesses": accesses})
+	}
+	c.JSON(http.StatusOK, links)
+}
+
+// RevokeShareLink stops a link from working before it expires
+func RevokeShareLink(c *gin.Context) {
+	link, exists := models.ShareLinks[c.Param("id")]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+		return
+	}
+
+	if link.RevokedAt == nil {
+		now := time.Now()
+		link.RevokedAt = &now
+	}
+	c.JSON(http.StatusOK, link)
+}
+
+// recordShareLinkAccess adds a request to the link's audit trail
+func recordShareLinkAccess(c *gin.Context, linkID, outcome string) {
+	shareLinkMu.Lock()
+	defer shareLinkMu.Unlock()
+	models.ShareLinkAccesses[linkID] = append(models.ShareLinkAccesses[linkID], &models.ShareLinkAccess{
+		LinkID:     linkID,
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		Outcome:    outcome,
+		AccessedAt: time.Now(),
+	})
+}
+
+// reserveShareLinkDownload counts a download against the link before the
+// file is served, so parallel requests cannot all get past its limit. It
+// reports false when the link has been used up.
+func reserveShareLinkDownload(link *models.ShareLink) bool {
+	shareLinkMu.Lock()
+	defer shareLinkMu.Unlock()
+	if link.MaxDownloads > 0 && link.Downloads >= link.MaxDownloads {
+		return false
+	}
+	link.Downloads++
+	return true
+}
+
+// releaseShareLinkDownload gives back a reserved download when no file was sent
+func releaseShareLinkDownload(link *models.ShareLink) {
+	shareLinkMu.Lock()
+	defer shareLinkMu.Unlock()
+	link.Downloads--
+}
+
+// DownloadSharedDocument serves a shared document without authentication
+// once the link's signature, expiry and download limit have been checked
+func DownloadSharedDocument(c *gin.Context) {
+	linkID := c.Param("id")
+	link, exists := models.ShareLinks[linkID]
+	if !exists {
+		log.Printf("Request for unknown share link %s from %s", linkID, c.ClientIP())
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid share link"})
+		return
+	}
+
+	refuse := func(status int, outcome, message string) {
+		recordShareLinkAccess(c, link.ID, outcome)
+		c.JSON(status, gin.H{"error": message})
+	}
+
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	signature, _ := hex.DecodeString(c.Query("signature"))
+	expected, _ := hex.DecodeString(signShareLink(link.ID, link.ExpiresAt.Unix()))
+	if err != nil || expires != link.ExpiresAt.Unix() || !hmac.Equal(signature, expected) {
+		refuse(http.StatusForbidden, "invalid_signature", "Invalid share link")
+		return
+	}
+	if link.RevokedAt != nil {
+		refuse(http.StatusGone, "revoked", "Share link has been revoked")
+		return
+	}
+	if time.Now().After(link.ExpiresAt) {
+		refuse(http.StatusGone, "expired", "Share link has expired")
+		return
+	}
+	doc, exists := lookupDocument(link.DocumentID)
+	if !exists {
+		refuse(http.StatusGone, "document_deleted", "Document is no longer available")
+		return
+	}
+	if !reserveShareLinkDownload(link) {
+		refuse(http.StatusGone, "exhausted", "Share link has been used up")
+		return
+	}
+	if doc.Versions[link.Version-1].ScanStatus != models.ScanClean {
+		releaseShareLinkDownload(link)
+		refuse(http.StatusConflict, "not_clean", "File has not passed the malware scan")
+		return
+	}
+
+	// Links with a download limit always send the whole file, so that it
+	// cannot be fetched range by range for the price of one download
+	if link.MaxDownloads > 0 {
+		c.Request.Header.Del("Range")
+		c.Request.Header.Del("If-Range")
+	}
+	serveStoredFile(c, &doc.Versions[link.Version-1], true)
+
+	// Every response carrying the file counts as a download; cache
+	// revalidations and failures give the reserved download back
+	switch status := c.Writer.Status(); status {
+	case http.StatusOK:
+		recordShareLinkAccess(c, link.ID, "served")
+	case http.StatusPartialContent:
+		recordShareLinkAccess(c, link.ID, "served_range")
+	case http.StatusNotModified:
+		releaseShareLinkDownload(link)
+		recordShareLinkAccess(c, link.ID, "not_modified")
+	default:
+		releaseShareLinkDownload(link)
+		recordShareLinkAccess(c, link.ID, "failed_"+strconv.Itoa(status))
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"vendor-management/models"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SpendTotals are the amounts in one currency. Committed is the sum of
+// purchase order ceilings, Consumed what approved invoices have drawn from
+// them, and Invoiced and Paid are pre-tax invoice subtotals.
+type SpendTotals struct {
+	Committed float64 `json:"committed"`
+	Consumed  float64 `json:"consumed"`
+	Invoiced  float64 `json:"invoiced"`
+	Paid      float64 `json:"paid"`
+}
+
+type SpendSummary struct {
+	ID     string                  `json:"id"` // Empty for spend not linked to a project or department
+	Code   string                  `json:"code,omitempty"`
+	Name   string                  `json:"name"`
+	Budget float64                 `json:"budget"`
+	Spend  map[string]*SpendTotals `json:"spend"` // By currency
+}
+
+// GetSpendSummary totals purchase orders and approved invoices per project,
+// or per department with groupBy=department
+func GetSpendSummary(c *gin.Context) {
+	groupBy := c.DefaultQuery("groupBy", "project")
+	if groupBy != "project" && groupBy != "department" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "groupBy must be project or department"})
+		return
+	}
+
+	summaries := make(map[string]*SpendSummary)
+	summaryFor := func(id string) *SpendSummary {
+		if summary, exists := summaries[id]; exists {
+			return summary
+		}
+		summary := &SpendSummary{ID: id, Name: "Unassigned", Spend: make(map[string]*SpendTotals)}
+		if groupBy == "project" {
+			if project, exists := models.Projects[id]; exists {
+				summary.Code, summary.Name, summary.Budget = project.Code, project.Name, project.Budget
+			}
+		} else if department, exists := models.Departments[id]; exists {
+			summary.Code, summary.Name, summary.Budget = department.Code, department.Name, department.Budget
+		}
+		summaries[id] = summary
+		return summary
+	}
+	totalsFor := func(id, currency string) *SpendTotals {
+		summary := summaryFor(id)
+		if _, exists := summary.Spend[currency]; !exists {
+			summary.Spend[currency] = &SpendTotals{}
+		}
+		return summary.Spend[currency]
+	}
+
+	// Every project and department is listed, even before any spend
+	if groupBy == "project" {
+		for id := range models.Projects {
+			summaryFor(id)
+		}
+	} else {
+		for id := range models.Departments {
+			summaryFor(id)
+		}
+	}
+
+	for _, po := range models.PurchaseOrders {
+		id := po.ProjectID
+		if groupBy == "department" {
+			id = ""
+			if project, exists := models.Projects[po.ProjectID]; exists {
+				id = project.DepartmentID
+			} else if vendor, exists := models.Vendors[po.VendorID]; exists {
+				id = vendor.DepartmentID
+			}
+		}
+		totals := totalsFor(id, po.Currency)
+		totals.Committed += po.Amount
+		totals.Consumed += po.Consumed
+	}
+
+	for _, invoice := range models.Invoices {
+		if invoice.Status == models.InvoiceDraft {
+			continue
+		}
+		id := invoice.ProjectID
+		if groupBy == "department" {
+			id = invoice.DepartmentID
+		}
+		totals := totalsFor(id, invoice.Currency)
+		totals.Invoiced += invoice.Subtotal
+		if invoice.Status == models.InvoicePaid {
+			totals.Paid += invoice.Subtotal
+		}
+	}
+
+	result := make([]*SpendSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		for _, totals := range summary.Spend {
+			totals.Committed = utils.RoundMoney(totals.Committed)
+			totals.Consumed = utils.RoundMoney(totals.Consumed)
+			totals.Invoiced = utils.RoundMoney(totals.Invoiced)
+			totals.Paid = utils.RoundMoney(totals.Paid)
+		}
+		result = append(result, summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// markDeleted stamps a record as moved to the trash by the current user
+func markDeleted(c *gin.Context, deletedAt **time.Time, deletedBy *string) {
+	userID, _ := c.Get("userId")
+	now := time.Now()
+	*deletedAt = &now
+	*deletedBy, _ = userID.(string)
+}
+
+func DeleteVendor(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	id := c.Param("id")
+	vendor, exists := lookupVendor(id)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	if !checkIfMatch(c, vendor.Version) {
+		return
+	}
+
+	if vendor.LegalHold != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Vendor is under legal hold"})
+		return
+	}
+
+	for _, asset := range models.Assets {
+		if asset.AssignedTo == id && asset.DeletedAt == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Vendor still has assigned assets"})
+			return
+		}
+	}
+
+	markDeleted(c, &vendor.DeletedAt, &vendor.DeletedBy)
+	vendor.Version++
+	recordVendorRevision(c, vendor)
+
+	c.Status(http.StatusNoContent)
+}
+
+func DeleteAsset(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	id := c.Param("id")
+	asset, exists := lookupAsset(id)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Asset not found"})
+		return
+	}
+
+	if !checkIfMatch(c, asset.Version) {
+		return
+	}
+
+	if asset.Status == "assigned" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Asset must be returned before it is deleted"})
+		return
+	}
+
+	markDeleted(c, &asset.DeletedAt, &asset.DeletedBy)
+	asset.Version++
+
+	c.Status(http.StatusNoContent)
+}
+
+func RestoreVendor(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	id := c.Param("id")
+	vendor, exists := models.Vendors[id]
+	if !exists || vendor.DeletedAt == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found in trash"})
+		return
+	}
+
+	if !checkIfMatch(c, vendor.Version) {
+		return
+	}
+
+	vendor.DeletedAt = nil
+	vendor.DeletedBy = ""
+	vendor.Version++
+	recordVendorRevision(c, vendor)
+
+	setETag(c, vendor.Version)
+	c.JSON(http.StatusOK, vendor)
+}
+
+func RestoreAsset(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	id := c.Param("id")
+	asset, exists := models.Assets[id]
+	if !exists || asset.DeletedAt == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Asset not found in trash"})
+		return
+	}
+
+	if !checkIfMatch(c, asset.Version) {
+		return
+	}
+
+	asset.DeletedAt = nil
+	asset.DeletedBy = ""
+	asset.Version++
+
+	setETag(c, asset.Version)
+	c.JSON(http.StatusOK, asset)
+}
+
+func RestoreDocument(c *gin.Context) {
+	id := c.Param("id")
+	doc, exists := models.Documents[id]
+	if !exists || doc.DeletedAt == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found in trash"})
+		return
+	}
+
+	var vendor *models.Vendor
+	if doc.VendorID != "" {
+		vendor, exists = lookupVendor(doc.VendorID)
+		if !exists {
+			c.JSON(http.StatusConflict, gin.H{"error": "Vendor must be restored first"})
+			return
+		}
+	}
+
+	doc.DeletedAt = nil
+	doc.DeletedBy = ""
+	if vendor != nil {
+		vendor.Documents = append(vendor.Documents, *doc)
+		recheckCompliance(vendor)
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+// ListTrash returns every soft-deleted vendor, asset and document
+func ListTrash(c *gin.Context) {
+	vendors := make([]*models.Vendor, 0)
+	for _, vendor := range models.Vendors {
+		if vendor.DeletedAt != nil {
+			vendors = append(vendors, vendor)
+		}
+	}
+
+	assets := make([]*models.Asset, 0)
+	for _, asset := range models.Assets {
+		if asset.DeletedAt != nil {
+			assets = append(assets, asset)
+		}
+	}
+
+	documents := make([]*models.Document, 0)
+	for _, doc := range models.Documents {
+		if doc.DeletedAt != nil {
+			documents = append(documents, doc)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vendors":   vendors,
+		"assets":    assets,
+		"documents": documents,
+	})
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"vendor-management/config"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	pdfType  = "application/pdf"
+	jpegType = "image/jpeg"
+	pngType  = "image/png"
+	docxType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+)
+
+// allowedContentTypes lists the file formats accepted for each document type
+var allowedContentTypes = map[models.DocumentType][]string{
+	models.JoiningLetterDocument: {pdfType, docxType},
+	models.AgreementDocument:     {pdfType, docxType},
+	models.IDProofDocument:       {pdfType, jpegType, pngType},
+	models.InsuranceDocument:     {pdfType, jpegType, pngType},
+	models.NDADocument:           {pdfType, docxType},
+	models.OtherDocument:         {pdfType, jpegType, pngType, docxType},
+}
+
+// contentTypeExtensions lists the file name extensions accepted for each
+// sniffed format
+var contentTypeExtensions = map[string][]string{
+	pdfType:  {".pdf"},
+	jpegType: {".jpg", ".jpeg"},
+	pngType:  {".png"},
+	docxType: {".docx"},
+}
+
+// errUploadTooLarge is reported with 413 Request Entity Too Large
+var errUploadTooLarge = fmt.Errorf("File exceeds the maximum upload size of %d MB", config.MaxUploadSize>>20)
+
+// parseDocumentType validates the type of an uploaded document
+func parseDocumentType(value string) (models.DocumentType, error) {
+	for _, docType := range models.DocumentTypes {
+		if string(docType) == value {
+			return docType, nil
+		}
+	}
+	names := make([]string, len(models.DocumentTypes))
+	for i, docType := range models.DocumentTypes {
+		names[i] = string(docType)
+	}
+	return "", fmt.Errorf("Invalid document type, expected one of: %s", strings.Join(names, ", "))
+}
+
+// limitUploadSize rejects requests declaring a body larger than the upload
+// limit and caps how much of the body is read otherwise. It reports whether
+// the request may proceed.
+func limitUploadSize(c *gin.Context) bool {
+	// Leave room for the other form fields and multipart framing
+	limit := config.MaxUploadSize + 1<<20
+	if c.Request.ContentLength > limit {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": errUploadTooLarge.Error()})
+		return false
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+	return true
+}
+
+// sniffContentType identifies the format of an uploaded file from its
+// contents. DOCX files are ZIP archives and are told apart by their entries.
+func sniffContentType(file multipart.File, size int64) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	contentType := http.DetectContentType(head[:n])
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	if contentType == "application/zip" {
+		archive, err := zip.NewReader(file, size)
+		if err != nil {
+			return contentType, nil
+		}
+		hasTypes, hasDocument := false, false
+		for _, entry := range archive.File {
+			hasTypes = hasTypes || entry.Name == "[Content_Types].xml"
+			hasDocument = hasDocument || entry.Name == "word/document.xml"
+		}
+		if hasTypes && hasDocument {
+			contentType = docxType
+		}
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return contentType, nil
+}
+
+// validateUpload checks the size, sniffed format and extension of an uploaded
+// file against the rules for the document type and returns its content type
+// together with the HTTP status to use on failure
+func validateUpload(docType models.DocumentType, header *multipart.FileHeader, file multipart.File) (string, int, error) {
+	if header.Size > config.MaxUploadSize {
+		return "", http.StatusRequestEntityTooLarge, errUploadTooLarge
+	}
+
+	contentType, err := sniffContentType(file, header.Size)
+	if err != nil {
+		return "", http.StatusBadRequest, errors.New("File upload failed")
+	}
+
+	allowed := false
+	for _, candidate := range allowedContentTypes[docType] {
+		allowed = allowed || candidate == contentType
+	}
+	if !allowed {
+		return "", http.StatusUnsupportedMediaType, fmt.Errorf("%s files are not accepted for %s documents", contentType, docType)
+	}
+
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	for _, candidate := range contentTypeExtensions[contentType] {
+		if ext == candidate {
+			return contentType, 0, nil
+		}
+	}
+	return "", http.StatusBadRequest, fmt.Errorf("File extension %q does not match its %s contents", ext, contentType)
+}
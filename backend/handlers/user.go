@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateUserRequest holds the user fields editable by admins
+type UpdateUserRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=admin vendor"`
+}
+
+func GetUser(c *gin.Context) {
+	id := c.Param("id")
+	user, exists := models.Users[id]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, user)
+}
+
+// PatchUser applies a JSON Merge Patch to the user's editable fields
+func PatchUser(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	id := c.Param("id")
+	user, exists := models.Users[id]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	current := UpdateUserRequest{
+		Name:  user.Name,
+		Email: user.Email,
+		Role:  string(user.Role),
+	}
+	var req UpdateUserRequest
+	if err := bindMergePatch(c, current, &req); err != nil {
+		patchError(c, err)
+		return
+	}
+
+	if !checkIfMatch(c, user.Version) {
+		return
+	}
+
+	// Check the new email is not taken by another user
+	for _, u := range models.Users {
+		if u.ID != user.ID && u.Email == req.Email {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
+			return
+		}
+	}
+
+	user.Name = req.Name
+	user.Email = req.Email
+	user.Role = models.Role(req.Role)
+	user.Version++
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, user)
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 	"vendor-management/models"
 
@@ -9,62 +11,221 @@ import (
 )
 
 type CreateVendorRequest struct {
-	CompanyName string `json:"companyName" binding:"required"`
+	CompanyID   string `json:"companyId"`
+	CompanyName string `json:"companyName" binding:"required_without=CompanyID"`
+	Type        string `json:"type"`
 	JoiningDate string `json:"joiningDate" binding:"required"`
 	EndDate     string `json:"endDate"`
-	Department  string `json:"department" binding:"required"`
-	ProjectName string `json:"projectName" binding:"required"`
-}
+	// Department and project are given either by managed entity ID or by
+	// name, which is matched against managed department and project names
+	DepartmentID string `json:"departmentId"`
+	Department   string `json:"department" binding:"required_without_all=DepartmentID ProjectID"`
+	ProjectID    string `json:"projectId"`
+	ProjectName  string `json:"projectName" binding:"required_without=ProjectID"`
 
-func CreateVendor(c *gin.Context) {
-	var req CreateVendorRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+	CustomFields map[string]interface{} `json:"customFields"`
+}
 
+// applyVendorRequest validates the request dates and copies the editable
+// fields onto the vendor
+func applyVendorRequest(vendor *models.Vendor, req *CreateVendorRequest) error {
 	joiningDate, err := time.Parse("2006-01-02", req.JoiningDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid joining date format"})
-		return
+		return errors.New("Invalid joining date format")
 	}
 
 	var endDate time.Time
 	if req.EndDate != "" {
 		endDate, err = time.Parse("2006-01-02", req.EndDate)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
-			return
+			return errors.New("Invalid end date format")
 		}
 	}
 
+	customFields, err := validateCustomFields(vendorEntity, req.CustomFields)
+	if err != nil {
+		return err
+	}
+
+	// Workers of a managed company take its legal name
+	companyName := req.CompanyName
+	if req.CompanyID != "" {
+		company, exists := lookupCompany(req.CompanyID)
+		if !exists {
+			return errors.New("Company not found")
+		}
+		companyName = company.LegalName
+	}
+
+	department, err := resolveDepartment(req.DepartmentID, req.Department)
+	if err != nil && !keepsLegacyName(vendor.DepartmentID, vendor.Department, req.DepartmentID, req.Department) {
+		return err
+	}
+	project, err := resolveProject(req.ProjectID, req.ProjectName)
+	if err != nil && !keepsLegacyName(vendor.ProjectID, vendor.ProjectName, req.ProjectID, req.ProjectName) {
+		return err
+	}
+	if project != nil && project.DepartmentID != "" {
+		if department == nil && req.Department == "" {
+			department = models.Departments[project.DepartmentID]
+		} else if department == nil || department.ID != project.DepartmentID {
+			return errors.New("Project does not belong to the department")
+		}
+	}
+
+	departmentID, departmentName := "", req.Department
+	if department != nil {
+		departmentID, departmentName = department.ID, department.Name
+	}
+	projectID, projectName := "", req.ProjectName
+	if project != nil {
+		projectID, projectName = project.ID, project.Name
+	}
+
+	vendor.CompanyID = req.CompanyID
+	vendor.CompanyName = companyName
+	vendor.Type = req.Type
+	vendor.JoiningDate = joiningDate
+	vendor.EndDate = endDate
+	vendor.DepartmentID = departmentID
+	vendor.Department = departmentName
+	vendor.ProjectID = projectID
+	vendor.ProjectName = projectName
+	vendor.CustomFields = customFields
+	return nil
+}
+
+// keepsLegacyName reports whether an update leaves a free-text department or
+// project name from before it was managed unchanged. Such names are kept so
+// that the vendor's other fields can still be updated.
+func keepsLegacyName(currentID, currentName, id, name string) bool {
+	return currentID == "" && id == "" && name != "" && name == currentName
+}
+
+// vendorRequestFor returns the editable representation of a vendor that
+// merge patches are applied to
+func vendorRequestFor(vendor *models.Vendor) CreateVendorRequest {
+	return CreateVendorRequest{
+		CompanyID:    vendor.CompanyID,
+		CompanyName:  vendor.CompanyName,
+		Type:         vendor.Type,
+		JoiningDate:  formatExportDate(vendor.JoiningDate),
+		EndDate:      formatExportDate(vendor.EndDate),
+		DepartmentID: vendor.DepartmentID,
+		Department:   vendor.Department,
+		ProjectID:    vendor.ProjectID,
+		ProjectName:  vendor.ProjectName,
+
+		CustomFields: vendor.CustomFields,
+	}
+}
+
+func CreateVendor(c *gin.Context) {
+	var req CreateVendorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	vendor := &models.Vendor{
-		ID:          generateID(),
-		CompanyName: req.CompanyName,
-		JoiningDate: joiningDate,
-		EndDate:     endDate,
-		Department:  req.Department,
-		ProjectName: req.ProjectName,
-		Status:      "active",
-		Documents:   make([]models.Document, 0),
-		Assets:      make([]models.Asset, 0),
+		ID:        generateID(),
+		Status:    "active",
+		Version:   1,
+		Documents: make([]models.Document, 0),
+		Assets:    make([]models.Asset, 0),
+	}
+	if err := applyVendorRequest(vendor, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	models.Vendors[vendor.ID] = vendor
+	recordVendorRevision(c, vendor)
+	trackProjectAssignment(c, vendor)
+	setETag(c, vendor.Version)
 	c.JSON(http.StatusCreated, vendor)
 }
 
+// lookupVendor returns the vendor unless it does not exist or is in the trash
+func lookupVendor(id string) (*models.Vendor, bool) {
+	vendor, exists := models.Vendors[id]
+	if !exists || vendor.DeletedAt != nil {
+		return nil, false
+	}
+	return vendor, true
+}
+
+// vendorFilterFromQuery builds the vendor filter shared by ListVendors and
+// ExportVendors from the status, companyId, department, departmentId,
+// projectName, projectId and cf.<key> custom field query parameters
+func vendorFilterFromQuery(c *gin.Context) (func(*models.Vendor) bool, error) {
+	status := c.Query("status")
+	companyID := c.Query("companyId")
+	departmentID := c.Query("departmentId")
+	projectID := c.Query("projectId")
+	department := c.Query("department")
+	projectName := c.Query("projectName")
+	matchCustomFields, err := customFieldFilterFromQuery(c, vendorEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(v *models.Vendor) bool {
+		if v.DeletedAt != nil {
+			return false
+		}
+		if status != "" && !strings.EqualFold(v.Status, status) {
+			return false
+		}
+		if companyID != "" && v.CompanyID != companyID {
+			return false
+		}
+		if departmentID != "" && v.DepartmentID != departmentID {
+			return false
+		}
+		if projectID != "" && v.ProjectID != projectID {
+			return false
+		}
+		if department != "" && !strings.EqualFold(v.Department, department) {
+			return false
+		}
+		if projectName != "" && !strings.EqualFold(v.ProjectName, projectName) {
+			return false
+		}
+		return matchCustomFields(v.CustomFields)
+	}, nil
+}
+
 func ListVendors(c *gin.Context) {
+	match, err := vendorFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	vendors := make([]*models.Vendor, 0)
 	for _, vendor := range models.Vendors {
-		vendors = append(vendors, vendor)
+		if match(vendor) {
+			vendors = append(vendors, vendor)
+		}
 	}
 	c.JSON(http.StatusOK, vendors)
 }
 
 func GetVendor(c *gin.Context) {
 	id := c.Param("id")
-	vendor, exists := models.Vendors[id]
+
+	// Point-in-time lookups also cover vendors that have since been deleted
+	if c.Query("asOf") != "" {
+		if _, exists := models.Vendors[id]; !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+			return
+		}
+		getVendorAsOf(c, id)
+		return
+	}
+
+	vendor, exists := lookupVendor(id)
 	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
 		return
@@ -84,16 +245,26 @@ func GetVendor(c *gin.Context) {
 		vendorAttendance = records
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"vendor":     vendor,
 		"assets":     vendorAssets,
 		"attendance": vendorAttendance,
-	})
+		"rating":     vendorRating(vendor.ID),
+	}
+	if company, exists := lookupCompany(vendor.CompanyID); exists {
+		response["company"] = company
+	}
+
+	setETag(c, vendor.Version)
+	c.JSON(http.StatusOK, response)
 }
 
 func UpdateVendor(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
 	id := c.Param("id")
-	vendor, exists := models.Vendors[id]
+	vendor, exists := lookupVendor(id)
 	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
 		return
@@ -105,27 +276,61 @@ func UpdateVendor(c *gin.Context) {
 		return
 	}
 
-	joiningDate, err := time.Parse("2006-01-02", req.JoiningDate)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid joining date format"})
+	saveVendor(c, vendor, &req)
+}
+
+// PatchVendor applies a JSON Merge Patch to the vendor's editable fields
+func PatchVendor(c *gin.Context) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	id := c.Param("id")
+	vendor, exists := lookupVendor(id)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
 		return
 	}
 
-	var endDate time.Time
-	if req.EndDate != "" {
-		endDate, err = time.Parse("2006-01-02", req.EndDate)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format"})
-			return
-		}
+	var req CreateVendorRequest
+	if err := bindMergePatch(c, vendorRequestFor(vendor), &req); err != nil {
+		patchError(c, err)
+		return
 	}
 
-	vendor.CompanyName = req.CompanyName
-	vendor.JoiningDate = joiningDate
-	vendor.EndDate = endDate
-	vendor.Department = req.Department
-	vendor.ProjectName = req.ProjectName
+	// The patch base carries both the ID and the name of the department and
+	// project, so drop whichever the patch left stale
+	if req.Department != vendor.Department && req.DepartmentID == vendor.DepartmentID {
+		req.DepartmentID = ""
+	} else if req.DepartmentID != vendor.DepartmentID && req.Department == vendor.Department {
+		req.Department = ""
+	}
+	if req.ProjectName != vendor.ProjectName && req.ProjectID == vendor.ProjectID {
+		req.ProjectID = ""
+	} else if req.ProjectID != vendor.ProjectID && req.ProjectName == vendor.ProjectName {
+		req.ProjectName = ""
+	}
+
+	saveVendor(c, vendor, &req)
+}
+
+// saveVendor applies an update to the vendor once its If-Match precondition
+// holds, bumping the version and recording the revision
+func saveVendor(c *gin.Context, vendor *models.Vendor, req *CreateVendorRequest) {
+	if !checkIfMatch(c, vendor.Version) {
+		return
+	}
+
+	updated := *vendor
+	if err := applyVendorRequest(&updated, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	updated.Version++
+	*vendor = updated
+	recordVendorRevision(c, vendor)
+	trackProjectAssignment(c, vendor)
 
+	setETag(c, vendor.Version)
 	c.JSON(http.StatusOK, vendor)
 }
 
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"time"
+	"vendor-management/models"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type vendorField struct {
+	Name  string
+	Value func(*models.Vendor) string
+}
+
+// vendorHistoryFields lists the vendor fields compared when building diffs
+var vendorHistoryFields = []vendorField{
+	{"userId", func(v *models.Vendor) string { return v.UserID }},
+	{"companyId", func(v *models.Vendor) string { return v.CompanyID }},
+	{"companyName", func(v *models.Vendor) string { return v.CompanyName }},
+	{"type", func(v *models.Vendor) string { return v.Type }},
+	{"joiningDate", func(v *models.Vendor) string { return formatExportDate(v.JoiningDate) }},
+	{"endDate", func(v *models.Vendor) string { return formatExportDate(v.EndDate) }},
+	{"departmentId", func(v *models.Vendor) string { return v.DepartmentID }},
+	{"department", func(v *models.Vendor) string { return v.Department }},
+	{"projectId", func(v *models.Vendor) string { return v.ProjectID }},
+	{"projectName", func(v *models.Vendor) string { return v.ProjectName }},
+	{"status", func(v *models.Vendor) string { return v.Status }},
+	{"legalHold", func(v *models.Vendor) string {
+		if v.LegalHold == nil {
+			return ""
+		}
+		return v.LegalHold.Reason
+	}},
+	{"deletedAt", func(v *models.Vendor) string {
+		if v.DeletedAt == nil {
+			return ""
+		}
+		return formatExportTime(*v.DeletedAt)
+	}},
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type VendorHistoryEntry struct {
+	Version   int           `json:"version"`
+	ChangedBy string        `json:"changedBy"`
+	ChangedAt time.Time     `json:"changedAt"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// recordVendorRevision stores a snapshot of the vendor attributed to the
+// user making the request. It must be called after every change to a vendor.
+func recordVendorRevision(c *gin.Context, vendor *models.Vendor) {
+	userID, _ := c.Get("userId")
+	changedBy, _ := userID.(string)
+	utils.RecordVendorRevision(vendor, changedBy)
+}
+
+// diffVendors returns the tracked fields that differ between two snapshots.
+// A nil previous snapshot reports every non-empty field as a change.
+func diffVendors(previous, current *models.Vendor) []FieldChange {
+	changes := make([]FieldChange, 0)
+	for _, field := range vendorHistoryFields {
+		from := ""
+		if previous != nil {
+			from = field.Value(previous)
+		}
+		to := field.Value(current)
+		if from != to {
+			changes = append(changes, FieldChange{Field: field.Name, From: from, To: to})
+		}
+	}
+
+	keys := make(map[string]bool)
+	if previous != nil {
+		for key := range previous.CustomFields {
+			keys[key] = true
+		}
+	}
+	for key := range current.CustomFields {
+		keys[key] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+	for _, key := range sortedKeys {
+		from := ""
+		if previous != nil {
+			from = formatCustomFieldValue(previous.CustomFields[key])
+		}
+		to := formatCustomFieldValue(current.CustomFields[key])
+		if from != to {
+			changes = append(changes, FieldChange{Field: customFieldQueryPrefix + key, From: from, To: to})
+		}
+	}
+	return changes
+}
+
+// vendorAsOf reconstructs the vendor from the latest revision recorded at or
+// before the given time
+func vendorAsOf(vendorID string, asOf time.Time) (*models.Vendor, bool) {
+	var found *models.Vendor
+	for _, rev := range models.VendorRevisions[vendorID] {
+		if rev.ChangedAt.After(asOf) {
+			break
+		}
+		snapshot := rev.Vendor
+		found = &snapshot
+	}
+	return found, found != nil
+}
+
+// parseAsOf accepts either a date, meaning the end of that day, or an RFC3339
+// timestamp
+func parseAsOf(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+func GetVendorHistory(c *gin.Context) {
+	id := c.Param("id")
+	if _, exists := models.Vendors[id]; !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor not found"})
+		return
+	}
+
+	history := make([]VendorHistoryEntry, 0)
+	var previous *models.Vendor
+	for _, rev := range models.VendorRevisions[id] {
+		current := rev.Vendor
+		history = append(history, VendorHistoryEntry{
+			Version:   rev.Version,
+			ChangedBy: rev.ChangedBy,
+			ChangedAt: rev.ChangedAt,
+			Changes:   diffVendors(previous, &current),
+		})
+		previous = &current
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// getVendorAsOf serves GetVendor when the asOf query parameter is present
+func getVendorAsOf(c *gin.Context, id string) {
+	asOf, err := parseAsOf(c.Query("asOf"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid asOf format"})
+		return
+	}
+
+	vendor, ok := vendorAsOf(id, asOf)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Vendor did not exist at the requested time"})
+		return
+	}
+
+	vendorAttendance := make([]*models.Attendance, 0)
+	for _, record := range models.AttendanceRecords[id] {
+		if !record.Date.After(asOf) {
+			vendorAttendance = append(vendorAttendance, record)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vendor":     vendor,
+		"asOf":       asOf,
+		"attendance": vendorAttendance,
+	})
+}
@@ -18,8 +18,9 @@ func main() {
 	// CORS middleware
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-Match"},
+		ExposeHeaders:    []string{"ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -31,12 +32,50 @@ func main() {
 	if err != nil {
 		log.Fatal("Error setting up cron job:", err)
 	}
+	// Send performance review reminders at 9 AM every day
+	_, err = c.AddFunc("0 9 * * *", utils.SendReviewReminders)
+	if err != nil {
+		log.Fatal("Error setting up cron job:", err)
+	}
+	// Purge expired trash at 2 AM every day
+	_, err = c.AddFunc("0 2 * * *", utils.PurgeTrash)
+	if err != nil {
+		log.Fatal("Error setting up cron job:", err)
+	}
+	// Check vendor document compliance at 1 AM every day
+	_, err = c.AddFunc("0 1 * * *", utils.CheckCompliance)
+	if err != nil {
+		log.Fatal("Error setting up cron job:", err)
+	}
+	// Invoice the previous month at 6 AM on the first of every month
+	_, err = c.AddFunc("0 6 1 * *", utils.RunMonthlyInvoices)
+	if err != nil {
+		log.Fatal("Error setting up cron job:", err)
+	}
+	// Verify stored document files at 3 AM every day
+	_, err = c.AddFunc("0 3 * * *", utils.RunIntegrityScan)
+	if err != nil {
+		log.Fatal("Error setting up cron job:", err)
+	}
+	// Scan files the malware scanner has not checked yet every 15 minutes
+	_, err = c.AddFunc("*/15 * * * *", utils.RescanDocuments)
+	if err != nil {
+		log.Fatal("Error setting up cron job:", err)
+	}
+	// Dispose of documents past retention at 4 AM every day
+	_, err = c.AddFunc("0 4 * * *", utils.RunDisposal)
+	if err != nil {
+		log.Fatal("Error setting up cron job:", err)
+	}
 	c.Start()
 
 	// Auth routes
 	r.POST("/api/auth/login", handlers.HandleLogin)
 	r.POST("/api/auth/signup", handlers.HandleSignup)
 
+	// Share links are authorized by their signature instead of a token
+	r.GET("/api/shared/documents/:id", handlers.DownloadSharedDocument)
+
 	// Protected routes
 	api := r.Group("/api")
 	api.Use(middleware.AuthMiddleware())
@@ -46,32 +85,148 @@ func main() {
 		api.GET("/my-attendance", handlers.GetMyAttendance)
 		api.GET("/my-assets", handlers.GetMyAssets)
 		api.GET("/my-documents", handlers.GetMyDocuments)
+		api.POST("/my-documents", handlers.UploadMyDocument)
+		api.GET("/my-documents/search", handlers.SearchMyDocuments)
+		api.GET("/my-documents/:id/download", handlers.DownloadMyDocument)
+		api.GET("/notifications", handlers.GetMyNotifications)
+		api.POST("/notifications/:id/read", handlers.MarkNotificationRead)
 
 		// Admin routes
 		admin := api.Group("/admin")
 		admin.Use(middleware.AdminMiddleware())
 		{
+			// Company management
+			admin.POST("/companies", handlers.CreateCompany)
+			admin.GET("/companies", handlers.ListCompanies)
+			admin.GET("/companies/:id", handlers.GetCompany)
+			admin.PUT("/companies/:id", handlers.UpdateCompany)
+			admin.GET("/companies/:id/workers", handlers.ListCompanyWorkers)
+			admin.GET("/companies/:id/summary", handlers.GetCompanySummary)
+			admin.GET("/companies/:id/rates", handlers.ListCompanyRates)
+			admin.POST("/companies/:id/rates", handlers.CreateCompanyRate)
+
+			// Departments and projects
+			admin.POST("/departments", handlers.CreateDepartment)
+			admin.GET("/departments", handlers.ListDepartments)
+			admin.GET("/departments/:id", handlers.GetDepartment)
+			admin.PUT("/departments/:id", handlers.UpdateDepartment)
+			admin.POST("/projects", handlers.CreateProject)
+			admin.GET("/projects", handlers.ListProjects)
+			admin.GET("/projects/:id", handlers.GetProject)
+			admin.PUT("/projects/:id", handlers.UpdateProject)
+			admin.GET("/projects/:id/roster", handlers.GetProjectRoster)
+
 			// Vendor management
 			admin.POST("/vendors", handlers.CreateVendor)
 			admin.GET("/vendors", handlers.ListVendors)
+			admin.GET("/vendors/export", handlers.ExportVendors)
 			admin.GET("/vendors/:id", handlers.GetVendor)
 			admin.PUT("/vendors/:id", handlers.UpdateVendor)
+			admin.PATCH("/vendors/:id", handlers.PatchVendor)
+			admin.DELETE("/vendors/:id", handlers.DeleteVendor)
+			admin.POST("/vendors/:id/restore", handlers.RestoreVendor)
+			admin.GET("/vendors/:id/history", handlers.GetVendorHistory)
+			admin.GET("/vendors/:id/assignments", handlers.GetVendorAssignments)
+			admin.POST("/vendors/:id/reviews", handlers.CreateReview)
+			admin.GET("/vendors/:id/reviews", handlers.ListVendorReviews)
+			admin.GET("/vendors/:id/compliance", handlers.GetVendorCompliance)
+			admin.GET("/vendors/:id/rates", handlers.ListVendorRates)
+			admin.POST("/vendors/:id/rates", handlers.CreateVendorRate)
+			admin.PUT("/vendors/:id/legal-hold", handlers.PlaceVendorLegalHold)
+			admin.DELETE("/vendors/:id/legal-hold", handlers.ReleaseVendorLegalHold)
+
+			// Compliance
+			admin.GET("/compliance", handlers.ListCompliance)
+			admin.POST("/compliance/check", handlers.RunComplianceCheck)
+			admin.GET("/compliance/rules", handlers.ListComplianceRules)
+			admin.POST("/compliance/rules", handlers.CreateComplianceRule)
+			admin.DELETE("/compliance/rules/:id", handlers.DeleteComplianceRule)
+
+			// Invoicing
+			admin.POST("/invoices/run", handlers.RunInvoices)
+			admin.GET("/invoices", handlers.ListInvoices)
+			admin.GET("/invoices/:id", handlers.GetInvoice)
+			admin.GET("/invoices/:id/pdf", handlers.GetInvoicePDF)
+			admin.POST("/invoices/:id/approve", handlers.ApproveInvoice)
+			admin.POST("/invoices/:id/paid", handlers.MarkInvoicePaid)
+			admin.POST("/purchase-orders", handlers.CreatePurchaseOrder)
+			admin.GET("/purchase-orders", handlers.ListPurchaseOrders)
+			admin.GET("/purchase-orders/:id", handlers.GetPurchaseOrder)
+			admin.PUT("/purchase-orders/:id", handlers.UpdatePurchaseOrder)
+			admin.GET("/spend", handlers.GetSpendSummary)
+			admin.GET("/tax-rules", handlers.ListTaxRules)
+			admin.POST("/tax-rules", handlers.CreateTaxRule)
+			admin.DELETE("/tax-rules/:id", handlers.DeleteTaxRule)
+
+			// Performance reviews
+			admin.GET("/review-criteria", handlers.ListReviewCriteria)
+			admin.POST("/review-criteria", handlers.CreateReviewCriterion)
+			admin.DELETE("/review-criteria/:id", handlers.DeleteReviewCriterion)
+			admin.GET("/reviews/leaderboard", handlers.GetReviewLeaderboard)
+
+			// Custom field definitions
+			admin.GET("/custom-fields", handlers.ListCustomFields)
+			admin.POST("/custom-fields", handlers.CreateCustomField)
+			admin.DELETE("/custom-fields/:id", handlers.DeleteCustomField)
+
+			// User management
+			admin.GET("/users/:id", handlers.GetUser)
+			admin.PATCH("/users/:id", handlers.PatchUser)
 
 			// Asset management
 			admin.POST("/assets", handlers.CreateAsset)
 			admin.GET("/assets", handlers.ListAssets)
+			admin.GET("/assets/export", handlers.ExportAssets)
 			admin.PUT("/assets/:id", handlers.UpdateAsset)
+			admin.PATCH("/assets/:id", handlers.PatchAsset)
+			admin.DELETE("/assets/:id", handlers.DeleteAsset)
+			admin.POST("/assets/:id/restore", handlers.RestoreAsset)
 			admin.POST("/assets/:id/assign", handlers.AssignAsset)
 			admin.POST("/assets/:id/return", handlers.ReturnAsset)
 
 			// Document management
 			admin.POST("/documents", handlers.UploadDocument)
 			admin.GET("/documents", handlers.ListDocuments)
+			admin.GET("/documents/export", handlers.ExportDocuments)
+			admin.GET("/documents/bundle", handlers.ExportDocumentBundle)
+			admin.GET("/documents/search", handlers.SearchDocuments)
+			admin.GET("/documents/integrity", handlers.GetIntegrityReport)
+			admin.GET("/documents/pending", handlers.ListPendingDocuments)
+			admin.POST("/documents/integrity/scan", handlers.RunIntegrityScan)
 			admin.GET("/documents/:id", handlers.GetDocument)
 			admin.DELETE("/documents/:id", handlers.DeleteDocument)
+			admin.GET("/documents/:id/preview", handlers.GetDocumentPreview)
+			admin.GET("/documents/:id/versions", handlers.ListDocumentVersions)
+			admin.POST("/documents/:id/versions", handlers.AddDocumentVersion)
+			admin.GET("/documents/:id/versions/:version", handlers.GetDocumentVersion)
+			admin.PUT("/documents/:id/current", handlers.SetCurrentDocumentVersion)
+			admin.POST("/documents/:id/restore", handlers.RestoreDocument)
+			admin.POST("/documents/:id/approve", handlers.ApproveDocument)
+			admin.POST("/documents/:id/reject", handlers.RejectDocument)
+			admin.PUT("/documents/:id/visibility", handlers.SetDocumentVisibility)
+			admin.POST("/documents/:id/share", handlers.CreateShareLink)
+			admin.GET("/documents/:id/shares", handlers.ListShareLinks)
+			admin.PUT("/documents/:id/legal-hold", handlers.PlaceDocumentLegalHold)
+			admin.DELETE("/documents/:id/legal-hold", handlers.ReleaseDocumentLegalHold)
+			admin.DELETE("/shares/:id", handlers.RevokeShareLink)
+
+			// Retention
+			admin.GET("/retention/rules", handlers.ListRetentionRules)
+			admin.POST("/retention/rules", handlers.CreateRetentionRule)
+			admin.DELETE("/retention/rules/:id", handlers.DeleteRetentionRule)
+			admin.POST("/retention/run", handlers.RunDisposal)
+			admin.GET("/retention/certificates", handlers.ListDisposalCertificates)
+			admin.GET("/retention/certificates/:id", handlers.GetDisposalCertificate)
+
+			// Trash
+			admin.GET("/trash", handlers.ListTrash)
+			admin.GET("/quarantine", handlers.ListQuarantine)
+			admin.POST("/quarantine/rescan", handlers.RunRescan)
+			admin.DELETE("/quarantine/:id", handlers.DeleteQuarantinedFile)
 
 			// Attendance management
 			admin.GET("/attendance", handlers.ListAttendance)
+			admin.GET("/attendance/export", handlers.ExportAttendance)
 			admin.GET("/attendance/:vendorId", handlers.GetVendorAttendance)
 		}
 	}
@@ -22,39 +22,171 @@ type User struct {
 	Password  string    `json:"-"` // Never sent in JSON responses
 	Role      Role      `json:"role"`
 	CreatedAt time.Time `json:"createdAt"`
+	Version   int       `json:"version"`
 }
 
+// Company is a supplying organisation. Its individual contractor workers are
+// Vendor records linked through CompanyID.
+type Company struct {
+	ID          string       `json:"id"`
+	LegalName   string       `json:"legalName"`
+	TradeName   string       `json:"tradeName,omitempty"`
+	TaxIDs      []TaxID      `json:"taxIds"`
+	Addresses   []Address    `json:"addresses"`
+	Contacts    []Contact    `json:"contacts"`
+	BankDetails *BankAccount `json:"bankDetails,omitempty"`
+	Status      string       `json:"status"` // active, inactive
+	CreatedAt   time.Time    `json:"createdAt"`
+	Version     int          `json:"version"`
+}
+
+type TaxID struct {
+	Type  string `json:"type"` // GST, PAN, VAT, etc.
+	Value string `json:"value"`
+}
+
+type Address struct {
+	Type       string `json:"type"` // registered, billing, office
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postalCode,omitempty"`
+	Country    string `json:"country"`
+}
+
+type Contact struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+	Role  string `json:"role,omitempty"` // account manager, billing, etc.
+}
+
+type BankAccount struct {
+	AccountName   string `json:"accountName"`
+	AccountNumber string `json:"accountNumber"`
+	BankName      string `json:"bankName"`
+	BranchCode    string `json:"branchCode,omitempty"` // IFSC, sort code or routing number
+}
+
+// Vendor is an individual contractor worker. Workers supplied by a managed
+// company reference it through CompanyID; CompanyName mirrors its legal name.
 type Vendor struct {
-	ID          string     `json:"id"`
-	UserID      string     `json:"userId"`
-	CompanyName string     `json:"companyName"`
-	JoiningDate time.Time  `json:"joiningDate"`
-	EndDate     time.Time  `json:"endDate,omitempty"`
-	Department  string     `json:"department"`
-	ProjectName string     `json:"projectName"`
-	Status      string     `json:"status"` // active, inactive
-	Documents   []Document `json:"documents"`
-	Assets      []Asset    `json:"assets"`
+	ID           string                 `json:"id"`
+	UserID       string                 `json:"userId"`
+	CompanyID    string                 `json:"companyId,omitempty"`
+	CompanyName  string                 `json:"companyName"`
+	Type         string                 `json:"type,omitempty"` // contractor, consultant, etc.; selects required documents
+	JoiningDate  time.Time              `json:"joiningDate"`
+	EndDate      time.Time              `json:"endDate,omitempty"`
+	Department   string                 `json:"department"`
+	DepartmentID string                 `json:"departmentId,omitempty"`
+	ProjectName  string                 `json:"projectName"`
+	ProjectID    string                 `json:"projectId,omitempty"`
+	Status       string                 `json:"status"` // active, inactive, suspended
+	Version      int                    `json:"version"`
+	DeletedAt    *time.Time             `json:"deletedAt,omitempty"`
+	DeletedBy    string                 `json:"deletedBy,omitempty"`
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
+	LegalHold    *LegalHold             `json:"legalHold,omitempty"` // Blocks deletion of the vendor and its documents
+	Documents    []Document             `json:"documents"`
+	Assets       []Asset                `json:"assets"`
 }
 
+type DocumentType string
+
+const (
+	JoiningLetterDocument DocumentType = "joining_letter"
+	AgreementDocument     DocumentType = "agreement"
+	IDProofDocument       DocumentType = "id_proof"
+	InsuranceDocument     DocumentType = "insurance"
+	NDADocument           DocumentType = "nda"
+	OtherDocument         DocumentType = "other"
+)
+
+// DocumentTypes lists the valid document types
+var DocumentTypes = []DocumentType{
+	JoiningLetterDocument, AgreementDocument, IDProofDocument, InsuranceDocument, NDADocument, OtherDocument,
+}
+
+// DocumentStatus tracks the review of documents uploaded by vendors.
+// Documents uploaded by admins are approved straight away.
+type DocumentStatus string
+
+const (
+	DocumentPending  DocumentStatus = "pending"
+	DocumentApproved DocumentStatus = "approved"
+	DocumentRejected DocumentStatus = "rejected"
+)
+
+// ScanStatus is the malware scan verdict on a stored file. Only clean files
+// are served.
+type ScanStatus string
+
+const (
+	ScanPending  ScanStatus = "pending" // Not scanned yet, e.g. the scanner was unavailable
+	ScanClean    ScanStatus = "clean"
+	ScanInfected ScanStatus = "infected" // Moved to quarantine
+)
+
+// Document belongs either to a worker (VendorID) or, for company-level
+// documents, to a company (CompanyID). Name, StorageKey, ContentType, Size and
+// the validity dates mirror the current version.
 type Document struct {
-	ID         string    `json:"id"`
-	VendorID   string    `json:"vendorId"`
-	Name       string    `json:"name"`
-	Type       string    `json:"type"` // joining_letter, agreement, id_proof
-	FilePath   string    `json:"filePath"`
-	UploadedAt time.Time `json:"uploadedAt"`
+	ID          string       `json:"id"`
+	VendorID    string       `json:"vendorId,omitempty"`
+	CompanyID   string       `json:"companyId,omitempty"`
+	Name        string       `json:"name"`
+	Type        DocumentType `json:"type"`
+	StorageKey  string       `json:"storageKey"` // Key in the storage backend
+	ContentType string       `json:"contentType"`
+	Size        int64        `json:"size"`
+	Checksum    string       `json:"checksum"` // Hex SHA-256 of the file contents
+	ScanStatus  ScanStatus   `json:"scanStatus"`
+	UploadedAt  time.Time    `json:"uploadedAt"`
+	ValidFrom   time.Time    `json:"validFrom,omitempty"`
+	ExpiresAt   time.Time    `json:"expiresAt,omitempty"` // Zero if the document does not expire
+	AdminOnly   bool         `json:"adminOnly"`           // Hidden from the vendor, e.g. background checks
+	LegalHold   *LegalHold   `json:"legalHold,omitempty"` // Blocks deletion and disposal
+	DeletedAt   *time.Time   `json:"deletedAt,omitempty"`
+	DeletedBy   string       `json:"deletedBy,omitempty"`
+
+	Status          DocumentStatus `json:"status"`
+	ReviewedBy      string         `json:"reviewedBy,omitempty"` // UserID
+	ReviewedAt      *time.Time     `json:"reviewedAt,omitempty"`
+	RejectionReason string         `json:"rejectionReason,omitempty"`
+
+	CurrentVersion int               `json:"currentVersion"`
+	Versions       []DocumentVersion `json:"versions"` // Oldest first, files of every version are kept
+}
+
+type DocumentVersion struct {
+	Version     int        `json:"version"`
+	Name        string     `json:"name"`
+	StorageKey  string     `json:"storageKey"`
+	ContentType string     `json:"contentType"` // Sniffed from the file contents
+	Size        int64      `json:"size"`
+	Checksum    string     `json:"checksum"`
+	ScanStatus  ScanStatus `json:"scanStatus"`
+	ValidFrom   time.Time  `json:"validFrom,omitempty"`
+	ExpiresAt   time.Time  `json:"expiresAt,omitempty"`
+	UploadedBy  string     `json:"uploadedBy"` // UserID
+	UploadedAt  time.Time  `json:"uploadedAt"`
 }
 
 type Asset struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	Type         string    `json:"type"` // laptop, monitor, keyboard, etc.
-	SerialNumber string    `json:"serialNumber"`
-	AssignedTo   string    `json:"assignedTo"` // VendorID
-	AssignedAt   time.Time `json:"assignedAt"`
-	ReturnedAt   time.Time `json:"returnedAt,omitempty"`
-	Status       string    `json:"status"` // assigned, available, maintenance
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Type         string                 `json:"type"` // laptop, monitor, keyboard, etc.
+	SerialNumber string                 `json:"serialNumber"`
+	AssignedTo   string                 `json:"assignedTo"` // VendorID
+	AssignedAt   time.Time              `json:"assignedAt"`
+	ReturnedAt   time.Time              `json:"returnedAt,omitempty"`
+	Status       string                 `json:"status"` // assigned, available, maintenance
+	Version      int                    `json:"version"`
+	DeletedAt    *time.Time             `json:"deletedAt,omitempty"`
+	DeletedBy    string                 `json:"deletedBy,omitempty"`
+	CustomFields map[string]interface{} `json:"customFields,omitempty"`
 }
 
 type Attendance struct {
@@ -67,15 +199,386 @@ type Attendance struct {
 	Status     string    `json:"status"`     // Present, Absent, Leave
 }
 
+// VendorRevision is a snapshot of a vendor taken each time it is created or
+// updated, used to answer point-in-time questions about the vendor
+type VendorRevision struct {
+	VendorID  string    `json:"vendorId"`
+	Version   int       `json:"version"`
+	Vendor    Vendor    `json:"vendor"`
+	ChangedBy string    `json:"changedBy"` // UserID
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+type CustomFieldType string
+
+const (
+	TextField    CustomFieldType = "text"
+	NumberField  CustomFieldType = "number"
+	DateField    CustomFieldType = "date"
+	EnumField    CustomFieldType = "enum"
+	BooleanField CustomFieldType = "boolean"
+)
+
+// CustomFieldDefinition describes an admin-defined attribute stored in the
+// CustomFields map of vendors or assets
+type CustomFieldDefinition struct {
+	ID        string          `json:"id"`
+	Entity    string          `json:"entity"` // vendor, asset
+	Key       string          `json:"key"`
+	Label     string          `json:"label"`
+	Type      CustomFieldType `json:"type"`
+	Required  bool            `json:"required"`
+	Options   []string        `json:"options,omitempty"` // Allowed values for enum fields
+	Min       *float64        `json:"min,omitempty"`     // Bounds for number fields
+	Max       *float64        `json:"max,omitempty"`
+	Pattern   string          `json:"pattern,omitempty"`   // Regular expression for text fields
+	MaxLength int             `json:"maxLength,omitempty"` // Maximum length for text fields
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// Department is a managed organisational unit that vendors are assigned to
+type Department struct {
+	ID        string    `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	OwnerID   string    `json:"ownerId,omitempty"` // UserID
+	Budget    float64   `json:"budget"`
+	CreatedAt time.Time `json:"createdAt"`
+	Version   int       `json:"version"`
+}
+
+// Project is a managed engagement within a department
+type Project struct {
+	ID           string    `json:"id"`
+	Code         string    `json:"code"`
+	Name         string    `json:"name"`
+	DepartmentID string    `json:"departmentId,omitempty"`
+	OwnerID      string    `json:"ownerId,omitempty"` // UserID
+	Budget       float64   `json:"budget"`
+	StartDate    time.Time `json:"startDate"`
+	EndDate      time.Time `json:"endDate,omitempty"`
+	Status       string    `json:"status"` // planned, active, closed
+	CreatedAt    time.Time `json:"createdAt"`
+	Version      int       `json:"version"`
+}
+
+// ProjectAssignment records a period during which a vendor worked on a
+// project. EndedAt is nil for the current assignment.
+type ProjectAssignment struct {
+	ID         string     `json:"id"`
+	VendorID   string     `json:"vendorId"`
+	ProjectID  string     `json:"projectId"`
+	AssignedBy string     `json:"assignedBy"` // UserID
+	StartedAt  time.Time  `json:"startedAt"`
+	EndedAt    *time.Time `json:"endedAt,omitempty"`
+}
+
+// ReviewCriterion is an admin-configurable dimension vendors are scored on
+type ReviewCriterion struct {
+	ID     string  `json:"id"`
+	Key    string  `json:"key"`
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+// PerformanceReview scores a vendor on every review criterion for a period.
+// Scores range from 1 to 5 and OverallScore is their weighted average.
+type PerformanceReview struct {
+	ID           string             `json:"id"`
+	VendorID     string             `json:"vendorId"`
+	ReviewerID   string             `json:"reviewerId"` // UserID
+	PeriodStart  time.Time          `json:"periodStart"`
+	PeriodEnd    time.Time          `json:"periodEnd"`
+	Scores       map[string]float64 `json:"scores"` // map[criterionKey]score
+	OverallScore float64            `json:"overallScore"`
+	Comments     string             `json:"comments"`
+	CreatedAt    time.Time          `json:"createdAt"`
+}
+
+// Notification is an in-app message for a user
+type Notification struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	Type      string    `json:"type"`            // review_due, etc.
+	RefID     string    `json:"refId,omitempty"` // ID of the record the notification is about
+	Message   string    `json:"message"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RateCard is the billing rate of a vendor or, as a default for all its
+// workers, of a company. Each new rate card supersedes the previous one from
+// its EffectiveFrom date.
+type RateCard struct {
+	ID                 string    `json:"id"`
+	VendorID           string    `json:"vendorId,omitempty"`
+	CompanyID          string    `json:"companyId,omitempty"`
+	Version            int       `json:"version"`
+	Unit               string    `json:"unit"` // hourly, daily, monthly
+	Rate               float64   `json:"rate"`
+	Currency           string    `json:"currency"` // ISO 4217 code
+	OvertimeMultiplier float64   `json:"overtimeMultiplier"`
+	EffectiveFrom      time.Time `json:"effectiveFrom"`
+	EffectiveTo        time.Time `json:"effectiveTo,omitempty"` // Zero while open-ended
+	CreatedBy          string    `json:"createdBy"`             // UserID
+	CreatedAt          time.Time `json:"createdAt"`
+}
+
+// TaxRule is a percentage tax added to invoices. An empty Currency applies
+// the rule to invoices in every currency.
+type TaxRule struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"` // GST, VAT, etc.
+	Rate     float64 `json:"rate"` // Percentage
+	Currency string  `json:"currency,omitempty"`
+}
+
+type InvoiceStatus string
+
+const (
+	InvoiceDraft    InvoiceStatus = "draft"
+	InvoiceApproved InvoiceStatus = "approved"
+	InvoicePaid     InvoiceStatus = "paid"
+)
+
+// Invoice bills a vendor for one month of attendance at its effective rates
+type Invoice struct {
+	ID              string            `json:"id"`
+	Number          string            `json:"number"`
+	VendorID        string            `json:"vendorId"`
+	CompanyID       string            `json:"companyId,omitempty"`
+	ProjectID       string            `json:"projectId,omitempty"`       // Vendor's project when invoiced
+	DepartmentID    string            `json:"departmentId,omitempty"`    // Vendor's department when invoiced
+	PurchaseOrderID string            `json:"purchaseOrderId,omitempty"` // Set when approved against a PO
+	PeriodStart     time.Time         `json:"periodStart"`
+	PeriodEnd       time.Time         `json:"periodEnd"`
+	Currency        string            `json:"currency"`
+	LineItems       []InvoiceLineItem `json:"lineItems"`
+	Subtotal        float64           `json:"subtotal"`
+	Taxes           []InvoiceTax      `json:"taxes"`
+	Total           float64           `json:"total"`
+	Status          InvoiceStatus     `json:"status"`
+	CreatedBy       string            `json:"createdBy"` // UserID, empty for scheduled runs
+	CreatedAt       time.Time         `json:"createdAt"`
+	ApprovedBy      string            `json:"approvedBy,omitempty"`
+	ApprovedAt      *time.Time        `json:"approvedAt,omitempty"`
+	PaidAt          *time.Time        `json:"paidAt,omitempty"`
+}
+
+type InvoiceLineItem struct {
+	Description string  `json:"description"`
+	RateCardID  string  `json:"rateCardId"`
+	Quantity    float64 `json:"quantity"`
+	Unit        string  `json:"unit"` // hour, day, month
+	UnitPrice   float64 `json:"unitPrice"`
+	Amount      float64 `json:"amount"`
+}
+
+type InvoiceTax struct {
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"`
+	Amount float64 `json:"amount"`
+}
+
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderOpen   PurchaseOrderStatus = "open"
+	PurchaseOrderClosed PurchaseOrderStatus = "closed"
+)
+
+// PurchaseOrder caps what may be billed for a vendor engagement. Approved
+// invoices consume the ceiling.
+type PurchaseOrder struct {
+	ID               string                     `json:"id"`
+	Number           string                     `json:"number"` // Issued by procurement
+	VendorID         string                     `json:"vendorId"`
+	ProjectID        string                     `json:"projectId,omitempty"` // Empty covers any project
+	Amount           float64                    `json:"amount"`              // Ceiling
+	Currency         string                     `json:"currency"`
+	Consumed         float64                    `json:"consumed"`
+	StartDate        time.Time                  `json:"startDate"`
+	EndDate          time.Time                  `json:"endDate,omitempty"`
+	Status           PurchaseOrderStatus        `json:"status"`
+	Consumptions     []PurchaseOrderConsumption `json:"consumptions"`
+	AlertedThreshold int                        `json:"alertedThreshold"` // Highest utilization percentage alerted on
+	CreatedBy        string                     `json:"createdBy"`        // UserID
+	CreatedAt        time.Time                  `json:"createdAt"`
+	Version          int                        `json:"version"`
+}
+
+type PurchaseOrderConsumption struct {
+	InvoiceID  string    `json:"invoiceId"`
+	Amount     float64   `json:"amount"`
+	ConsumedAt time.Time `json:"consumedAt"`
+}
+
+// ComplianceRule requires vendors of a type to hold a valid document of the
+// given type. An empty VendorType applies the rule to every vendor. Vendors
+// lacking a critical document are suspended until it is provided.
+type ComplianceRule struct {
+	ID           string       `json:"id"`
+	VendorType   string       `json:"vendorType,omitempty"`
+	DocumentType DocumentType `json:"documentType"`
+	Critical     bool         `json:"critical"`
+}
+
+type ComplianceState string
+
+const (
+	Compliant    ComplianceState = "compliant"
+	Expiring     ComplianceState = "expiring"
+	NonCompliant ComplianceState = "non_compliant"
+)
+
+// ComplianceStatus is the outcome of the latest compliance check of a vendor
+type ComplianceStatus struct {
+	VendorID  string          `json:"vendorId"`
+	State     ComplianceState `json:"state"`
+	Missing   []string        `json:"missing"`   // Required document types never provided
+	Expired   []string        `json:"expired"`   // Required document types whose documents have all lapsed
+	Expiring  []string        `json:"expiring"`  // Required document types lapsing within the warning window
+	Suspended bool            `json:"suspended"` // Vendor was suspended by the compliance check
+	CheckedAt time.Time       `json:"checkedAt"`
+}
+
+type IntegrityProblem string
+
+const (
+	FileMissing   IntegrityProblem = "missing"
+	FileCorrupted IntegrityProblem = "corrupted" // Contents no longer match the checksum
+)
+
+type IntegrityIssue struct {
+	DocumentID string           `json:"documentId"`
+	Version    int              `json:"version"`
+	StorageKey string           `json:"storageKey"`
+	Problem    IntegrityProblem `json:"problem"`
+}
+
+// IntegrityReport is the outcome of a scan of every stored document file
+type IntegrityReport struct {
+	CheckedAt time.Time        `json:"checkedAt"`
+	Files     int              `json:"files"`
+	Issues    []IntegrityIssue `json:"issues"`
+}
+
+// QuarantinedFile is a file the malware scanner flagged. Infected uploads are
+// refused and only kept here; infected files of existing documents found by
+// a rescan are moved here too and keep their document ID.
+type QuarantinedFile struct {
+	ID            string    `json:"id"`
+	DocumentID    string    `json:"documentId,omitempty"`
+	Version       int       `json:"version,omitempty"`
+	OwnerID       string    `json:"ownerId"` // VendorID or CompanyID
+	Name          string    `json:"name"`
+	StorageKey    string    `json:"storageKey"`
+	Signature     string    `json:"signature"`
+	UploadedBy    string    `json:"uploadedBy"` // UserID
+	QuarantinedAt time.Time `json:"quarantinedAt"`
+}
+
+// LegalHold keeps a vendor's documents, or a single document, from being
+// deleted, purged from the trash or disposed of until it is released
+type LegalHold struct {
+	Reason   string    `json:"reason"`
+	PlacedBy string    `json:"placedBy"` // UserID
+	PlacedAt time.Time `json:"placedAt"`
+}
+
+// RetentionRule keeps the documents of a type for a period after the vendor's
+// EndDate, after which the disposal job destroys them. Documents of vendors
+// without an EndDate and company-level documents are kept indefinitely.
+type RetentionRule struct {
+	ID           string       `json:"id"`
+	DocumentType DocumentType `json:"documentType"`
+	Years        int          `json:"years"`
+	Months       int          `json:"months"`
+	CreatedBy    string       `json:"createdBy"` // UserID
+	CreatedAt    time.Time    `json:"createdAt"`
+}
+
+// DisposedFile is one destroyed version of a disposed document
+type DisposedFile struct {
+	Version  int    `json:"version"`
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+}
+
+// DisposalCertificate records that a document was destroyed under a
+// retention rule. Certificates outlive the documents they describe.
+type DisposalCertificate struct {
+	ID           string         `json:"id"`
+	DocumentID   string         `json:"documentId"`
+	VendorID     string         `json:"vendorId"`
+	VendorName   string         `json:"vendorName"`
+	DocumentType DocumentType   `json:"documentType"`
+	Name         string         `json:"name"`
+	Files        []DisposedFile `json:"files"`
+	RuleID       string         `json:"ruleId"`
+	EndDate      time.Time      `json:"endDate"` // Vendor's EndDate the period ran from
+	RetainUntil  time.Time      `json:"retainUntil"`
+	DisposedAt   time.Time      `json:"disposedAt"`
+}
+
+// ShareLink lets someone without an account download one version of a
+// document until the link expires, runs out of downloads or is revoked
+type ShareLink struct {
+	ID           string     `json:"id"`
+	DocumentID   string     `json:"documentId"`
+	Version      int        `json:"version"` // Version current when the link was created
+	ExpiresAt    time.Time  `json:"expiresAt"`
+	MaxDownloads int        `json:"maxDownloads,omitempty"` // Zero allows any number
+	Downloads    int        `json:"downloads"`
+	CreatedBy    string     `json:"createdBy"` // UserID
+	CreatedAt    time.Time  `json:"createdAt"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty"`
+}
+
+// ShareLinkAccess records one request for a share link, whether or not the
+// file was served
+type ShareLinkAccess struct {
+	LinkID     string    `json:"linkId"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	Outcome    string    `json:"outcome"` // served, served_range, or why the file was not served
+	AccessedAt time.Time `json:"accessedAt"`
+}
+
 // In-memory storage (to be replaced with a real database later)
 var (
-	Users             = make(map[string]*User)
-	Vendors           = make(map[string]*Vendor)
-	Documents         = make(map[string]*Document)
-	Assets            = make(map[string]*Asset)
-	AttendanceRecords = make(map[string][]*Attendance) // map[vendorID][]Attendance
+	Users                = make(map[string]*User)
+	Companies            = make(map[string]*Company)
+	Vendors              = make(map[string]*Vendor)
+	Documents            = make(map[string]*Document)
+	Assets               = make(map[string]*Asset)
+	AttendanceRecords    = make(map[string][]*Attendance)     // map[vendorID][]Attendance
+	VendorRevisions      = make(map[string][]*VendorRevision) // map[vendorID][]VendorRevision, oldest first
+	CustomFields         = make(map[string]*CustomFieldDefinition)
+	Departments          = make(map[string]*Department)
+	Projects             = make(map[string]*Project)
+	ProjectAssignments   = make(map[string][]*ProjectAssignment) // map[vendorID][]ProjectAssignment, oldest first
+	ReviewCriteria       = make(map[string]*ReviewCriterion)
+	PerformanceReviews   = make(map[string][]*PerformanceReview) // map[vendorID][]PerformanceReview
+	Notifications        = make(map[string]*Notification)
+	RateCards            = make(map[string][]*RateCard) // map[vendorID or companyID][]RateCard, oldest first
+	TaxRules             = make(map[string]*TaxRule)
+	Invoices             = make(map[string]*Invoice)
+	PurchaseOrders       = make(map[string]*PurchaseOrder)
+	ComplianceRules      = make(map[string]*ComplianceRule)
+	ComplianceStatuses   = make(map[string]*ComplianceStatus) // map[vendorID]ComplianceStatus
+	ShareLinks           = make(map[string]*ShareLink)
+	QuarantinedFiles     = make(map[string]*QuarantinedFile)
+	RetentionRules       = make(map[string]*RetentionRule)
+	DisposalCertificates = make(map[string]*DisposalCertificate)
+	ShareLinkAccesses    = make(map[string][]*ShareLinkAccess) // map[linkID][]ShareLinkAccess, oldest first
 )
 
+// LastIntegrityReport is the result of the latest integrity scan, nil until
+// the first scan has run
+var LastIntegrityReport *IntegrityReport
+
 func init() {
 	// Create default admin user if not present
 	for _, u := range Users {
@@ -90,10 +593,21 @@ func init() {
 		Email:     "admin@company.com",
 		Role:      AdminRole,
 		CreatedAt: time.Now(),
+		Version:   1,
 	}
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("admin"), bcrypt.DefaultCost)
 	adminUser.Password = string(hashedPassword)
 	Users[adminID] = adminUser
+
+	// Default review criteria, which admins can change
+	for _, criterion := range []*ReviewCriterion{
+		{Key: "quality", Name: "Quality of work", Weight: 1},
+		{Key: "timeliness", Name: "Timeliness", Weight: 1},
+		{Key: "communication", Name: "Communication", Weight: 1},
+	} {
+		criterion.ID = generateID()
+		ReviewCriteria[criterion.ID] = criterion
+	}
 }
 
 // generateID generates a random ID (reused from handlers package)
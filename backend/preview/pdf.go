@@ -0,0 +1,158 @@
+package preview
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+	"vendor-management/config"
+)
+
+// renderTimeout bounds how long pdftoppm may take over one page
+const renderTimeout = time.Minute
+
+// pdfFirstPage renders the first page with pdftoppm when it is configured,
+// and otherwise uses the first image embedded in the file
+func pdfFirstPage(data []byte) (image.Image, error) {
+	if config.PDFRenderer != "" {
+		return renderPDFPage(config.PDFRenderer, data)
+	}
+	return firstPDFImage(data)
+}
+
+// renderPDFPage runs pdftoppm over a temporary copy of the file
+func renderPDFPage(renderer string, data []byte) (image.Image, error) {
+	dir, err := os.MkdirTemp("", "preview")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "document.pdf")
+	if err := os.WriteFile(input, data, 0600); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), renderTimeout)
+	defer cancel()
+	output := filepath.Join(dir, "page")
+	cmd := exec.CommandContext(ctx, renderer, "-f", "1", "-l", "1", "-singlefile", "-png",
+		"-scale-to", strconv.Itoa(Sizes[Page]), input, output)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("pdftoppm failed: %v: %s", err, bytes.TrimSpace(out))
+	}
+
+	page, err := os.ReadFile(output + ".png")
+	if err != nil {
+		return nil, err
+	}
+	return decodeImage(page)
+}
+
+var (
+	pdfStreamStart  = regexp.MustCompile(`>>\s*stream\r?\n`)
+	pdfImageSubtype = regexp.MustCompile(`/Subtype\s*/Image\b`)
+	pdfFilter       = regexp.MustCompile(`/Filter\s*(?:/(\w+)|\[\s*/(\w+)\s*\])`)
+	pdfColorSpace   = regexp.MustCompile(`/ColorSpace\s*/(\w+)`)
+	pdfPredictor    = regexp.MustCompile(`/Predictor\s+(\d+)`)
+	pdfWidth        = regexp.MustCompile(`/Width\s+(\d+)`)
+	pdfHeight       = regexp.MustCompile(`/Height\s+(\d+)`)
+	pdfBits         = regexp.MustCompile(`/BitsPerComponent\s+(\d+)`)
+)
+
+// firstPDFImage decodes the first image in the file that is JPEG-encoded, or
+// Flate-compressed 8-bit RGB or gray without a predictor. Scanners store each
+// page as one such image, so for scanned documents this is the first page.
+func firstPDFImage(data []byte) (image.Image, error) {
+	for _, loc := range pdfStreamStart.FindAllIndex(data, -1) {
+		// The dictionary starts after the header of the object owning the stream
+		header := bytes.LastIndex(data[:loc[0]], []byte("obj"))
+		if header < 0 {
+			continue
+		}
+		dict := data[header:loc[0]]
+		if !pdfImageSubtype.Match(dict) {
+			continue
+		}
+		end := bytes.Index(data[loc[1]:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		if img, err := decodePDFImage(dict, data[loc[1]:loc[1]+end]); err == nil {
+			return img, nil
+		}
+	}
+	return nil, ErrUnsupported
+}
+
+func decodePDFImage(dict, content []byte) (image.Image, error) {
+	filter := ""
+	if m := pdfFilter.FindSubmatch(dict); m != nil {
+		filter = string(m[1]) + string(m[2])
+	}
+	if filter == "DCTDecode" {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Width*cfg.Height > maxSourcePixels {
+			return nil, ErrUnsupported
+		}
+		return jpeg.Decode(bytes.NewReader(content))
+	}
+	if filter != "FlateDecode" || pdfPredictor.Match(dict) || pdfDictInt(pdfBits, dict) != 8 {
+		return nil, ErrUnsupported
+	}
+
+	width, height := pdfDictInt(pdfWidth, dict), pdfDictInt(pdfHeight, dict)
+	if width <= 0 || height <= 0 || width*height > maxSourcePixels {
+		return nil, ErrUnsupported
+	}
+	colorSpace := ""
+	if m := pdfColorSpace.FindSubmatch(dict); m != nil {
+		colorSpace = string(m[1])
+	}
+	r, err := zlib.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	switch colorSpace {
+	case "DeviceGray":
+		img := image.NewGray(image.Rect(0, 0, width, height))
+		if _, err := io.ReadFull(r, img.Pix); err != nil {
+			return nil, err
+		}
+		return img, nil
+	case "DeviceRGB":
+		samples := make([]byte, width*height*3)
+		if _, err := io.ReadFull(r, samples); err != nil {
+			return nil, err
+		}
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for i, j := 0, 0; i < len(samples); i, j = i+3, j+4 {
+			copy(img.Pix[j:j+3], samples[i:i+3])
+			img.Pix[j+3] = 0xff
+		}
+		return img, nil
+	}
+	return nil, ErrUnsupported
+}
+
+func pdfDictInt(key *regexp.Regexp, dict []byte) int {
+	m := key.FindSubmatch(dict)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(string(m[1]))
+	return n
+}
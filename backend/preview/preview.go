@@ -0,0 +1,124 @@
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+)
+
+// Size names one of the generated images
+type Size string
+
+const (
+	Page      Size = "preview"   // First page at a readable size
+	Thumbnail Size = "thumbnail" // Small image for lists
+)
+
+// Sizes maps each size to the longest side of its image in pixels
+var Sizes = map[Size]int{
+	Page:      1024,
+	Thumbnail: 200,
+}
+
+// maxSourcePixels guards against images that would take too much memory to
+// decode
+const maxSourcePixels = 50_000_000
+
+// ErrUnsupported is returned for files no preview can be made of, such as a
+// PDF without images when no renderer is configured
+var ErrUnsupported = errors.New("No preview can be generated for this file")
+
+// Supported reports whether previews are generated for a content type
+func Supported(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "application/pdf":
+		return true
+	}
+	return false
+}
+
+// Key returns where the image of a given size is cached, next to the
+// original file
+func Key(storageKey string, size Size) string {
+	return fmt.Sprintf("%s.%s.jpg", storageKey, size)
+}
+
+// FirstPage decodes an image, or the first page of a PDF
+func FirstPage(contentType string, data []byte) (image.Image, error) {
+	switch contentType {
+	case "image/jpeg", "image/png":
+		return decodeImage(data)
+	case "application/pdf":
+		return pdfFirstPage(data)
+	}
+	return nil, ErrUnsupported
+}
+
+func decodeImage(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > maxSourcePixels {
+		return nil, fmt.Errorf("Image of %dx%d pixels is too large to preview", cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// Fit scales an image down so that its longest side is at most longest pixels
+// and flattens it onto white, as JPEG has no transparency. Each output pixel
+// averages the source pixels it covers, which keeps text on scans legible.
+func Fit(img image.Image, longest int) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w > longest || h > longest {
+		if w >= h {
+			w, h = longest, bounds.Dy()*longest/bounds.Dx()
+		} else {
+			w, h = bounds.Dx()*longest/bounds.Dy(), longest
+		}
+	}
+	w, h = max(w, 1), max(h, 1)
+
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/h
+		y1 := max(bounds.Min.Y+(y+1)*bounds.Dy()/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/w
+			x1 := max(bounds.Min.X+(x+1)*bounds.Dx()/w, x0+1)
+
+			var r, g, b, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					// Colors are premultiplied, so adding the missing alpha
+					// composites them over white
+					r += uint64(pr + 0xffff - pa)
+					g += uint64(pg + 0xffff - pa)
+					b += uint64(pb + 0xffff - pa)
+					n++
+				}
+			}
+			i := out.PixOffset(x, y)
+			out.Pix[i] = uint8(r / n >> 8)
+			out.Pix[i+1] = uint8(g / n >> 8)
+			out.Pix[i+2] = uint8(b / n >> 8)
+			out.Pix[i+3] = 0xff
+		}
+	}
+	return out
+}
+
+// Encode returns the JPEG encoding of an image scaled to the given size
+func Encode(img image.Image, size Size) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, Fit(img, Sizes[size]), &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
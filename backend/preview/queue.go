@@ -0,0 +1,123 @@
+package preview
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"sync"
+	"vendor-management/config"
+	"vendor-management/storage"
+)
+
+type job struct {
+	storageKey  string
+	contentType string
+}
+
+// Previews are generated by a pool of background workers, started with the
+// first job. Only the storage keys being worked on and the files that cannot
+// be previewed are tracked; generated images are found in storage.
+var (
+	startWorkers sync.Once
+	jobs         = make(chan job, 100)
+	mu           sync.Mutex
+	queued       = make(map[string]bool) // Storage keys queued or being generated
+	unsupported  = make(map[string]bool) // Storage keys no preview can be made of
+)
+
+// Enqueue asks for the previews of a stored file to be generated in the
+// background. Files already queued are skipped.
+func Enqueue(storageKey, contentType string) {
+	if !Supported(contentType) {
+		return
+	}
+	startWorkers.Do(func() {
+		for i := 0; i < max(config.PreviewWorkers, 1); i++ {
+			go work()
+		}
+	})
+
+	mu.Lock()
+	if queued[storageKey] || unsupported[storageKey] {
+		mu.Unlock()
+		return
+	}
+	queued[storageKey] = true
+	mu.Unlock()
+
+	// Queue without holding up the request when the workers are behind
+	go func() { jobs <- job{storageKey, contentType} }()
+}
+
+// Pending reports whether the previews of a file are still being generated
+func Pending(storageKey string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return queued[storageKey]
+}
+
+// Unsupported reports whether generating the previews of a file failed
+// because no preview can be made of it
+func Unsupported(storageKey string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return unsupported[storageKey]
+}
+
+func work() {
+	for j := range jobs {
+		err := Generate(j.storageKey, j.contentType)
+		mu.Lock()
+		delete(queued, j.storageKey)
+		mu.Unlock()
+		if err != nil {
+			log.Printf("Failed to generate preview of %s: %v", j.storageKey, err)
+		}
+	}
+}
+
+// Generate renders and stores every preview size of a stored file. Files that
+// cannot be decoded are remembered so they are not retried; storage errors
+// are not, as they may pass.
+func Generate(storageKey, contentType string) error {
+	obj, err := storage.Store.Open(storageKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(obj)
+	obj.Close()
+	if err != nil {
+		return err
+	}
+
+	img, err := FirstPage(contentType, data)
+	if err != nil {
+		mu.Lock()
+		unsupported[storageKey] = true
+		mu.Unlock()
+		return err
+	}
+	for size := range Sizes {
+		encoded, err := Encode(img, size)
+		if err != nil {
+			return err
+		}
+		if err := storage.Store.Put(Key(storageKey, size), bytes.NewReader(encoded), int64(len(encoded))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove deletes the cached previews of a file that is being deleted
+func Remove(storageKey string) error {
+	for size := range Sizes {
+		if err := storage.Store.Delete(Key(storageKey, size)); err != nil {
+			return err
+		}
+	}
+	mu.Lock()
+	delete(unsupported, storageKey)
+	mu.Unlock()
+	return nil
+}
@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize is the size of the chunks streamed to clamd, well below
+// its default StreamMaxLength
+const clamdChunkSize = 64 << 10
+
+// ClamdScanner streams files to a ClamAV daemon with the INSTREAM command
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner connects to clamd at tcp://host:port or unix:///path
+func NewClamdScanner(address string, timeout time.Duration) (*ClamdScanner, error) {
+	switch {
+	case strings.HasPrefix(address, "tcp://"):
+		return &ClamdScanner{network: "tcp", address: strings.TrimPrefix(address, "tcp://"), timeout: timeout}, nil
+	case strings.HasPrefix(address, "unix://"):
+		return &ClamdScanner{network: "unix", address: strings.TrimPrefix(address, "unix://"), timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("clamd address must start with tcp:// or unix://: %s", address)
+	}
+}
+
+func (s *ClamdScanner) Scan(r io.Reader) (*Result, error) {
+	conn, err := net.DialTimeout(s.network, s.address, s.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(s.timeout))
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, err
+	}
+	chunk := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, err := r.Read(chunk)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(append(size, chunk[:n]...)); err != nil {
+				return nil, err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	// A zero-length chunk ends the stream
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return parseClamdReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// parseClamdReply reads replies such as "stream: OK" and
+// "stream: Eicar-Test-Signature FOUND"
+func parseClamdReply(reply string) (*Result, error) {
+	verdict := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case verdict == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &Result{Infected: true, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	case verdict == "":
+		return nil, errors.New("clamd closed the connection without a verdict")
+	default:
+		return nil, fmt.Errorf("clamd: %s", verdict)
+	}
+}
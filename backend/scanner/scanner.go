@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"vendor-management/config"
+)
+
+// Result is the verdict on one scanned file
+type Result struct {
+	Infected  bool
+	Signature string // Name of the malware found, when infected
+}
+
+// Scanner checks file contents for malware. An error means the file could
+// not be scanned, not that it is infected.
+type Scanner interface {
+	Scan(r io.Reader) (*Result, error)
+}
+
+// Default is the scanner selected by the SCANNER_BACKEND setting
+var Default Scanner
+
+func init() {
+	s, err := New(config.ScannerBackend)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize %s scanner: %v", config.ScannerBackend, err))
+	}
+	Default = s
+}
+
+// New creates the named scanner from the configuration
+func New(backend string) (Scanner, error) {
+	switch backend {
+	case "clamd":
+		return NewClamdScanner(config.ClamdAddress, config.ClamdTimeout)
+	case "stub":
+		log.Println("WARNING: SCANNER_BACKEND=stub only detects the EICAR test file and must not be used in production")
+		return StubScanner{}, nil
+	case "":
+		log.Println("WARNING: SCANNER_BACKEND is not set, uploads are withheld as awaiting a malware scan until a scanner is configured")
+		return unconfiguredScanner{}, nil
+	default:
+		return nil, fmt.Errorf("Unknown scanner backend: %s", backend)
+	}
+}
+
+// ErrNotConfigured is returned for every file when no scanner is configured
+var ErrNotConfigured = errors.New("No malware scanner is configured")
+
+// unconfiguredScanner fails closed: files it is asked about stay pending
+// rather than being trusted unscanned
+type unconfiguredScanner struct{}
+
+func (unconfiguredScanner) Scan(r io.Reader) (*Result, error) {
+	return nil, ErrNotConfigured
+}
+
+// eicar is the standard antivirus test file
+var eicar = []byte(`X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`)
+
+// StubScanner stands in for a real scanner in development and tests. It
+// reports the EICAR test file as infected and everything else as clean.
+type StubScanner struct{}
+
+func (StubScanner) Scan(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(data, eicar) {
+		return &Result{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+	}
+	return &Result{}, nil
+}
@@ -0,0 +1,268 @@
+package search
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// maxExtractedText caps how much text is kept per document
+const maxExtractedText = 1 << 20
+
+// ExtractText returns the readable text of a stored file. Formats without
+// text, such as scanned images, yield an empty string.
+func ExtractText(contentType string, data []byte) (string, error) {
+	var text string
+	var err error
+	switch contentType {
+	case "application/pdf":
+		text = extractPDFText(data)
+	case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+		text, err = extractDOCXText(data)
+	}
+	if len(text) > maxExtractedText {
+		text = strings.ToValidUTF8(text[:maxExtractedText], "")
+	}
+	return text, err
+}
+
+var pdfStream = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+
+// extractPDFText pulls the strings shown by text operators out of every
+// content stream. It handles the common uncompressed and Flate-compressed
+// streams; text in fonts with custom encodings comes out garbled or not at
+// all, which only costs search recall.
+func extractPDFText(data []byte) string {
+	var out strings.Builder
+	for _, loc := range pdfStream.FindAllSubmatchIndex(data, -1) {
+		dict := data[loc[2]:loc[3]]
+		start := loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		content := data[start : start+end]
+
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			r, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+			// Truncated streams still yield what was decompressed so far
+			content, _ = io.ReadAll(io.LimitReader(r, maxExtractedText))
+			r.Close()
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			continue
+		}
+		if bytes.Contains(content, []byte("BT")) {
+			extractPDFContent(content, &out)
+		}
+	}
+	return strings.TrimSpace(out.String())
+}
+
+// extractPDFContent walks a content stream, writing the operands of the
+// Tj, TJ, ' and " operators and a line break for each line move
+func extractPDFContent(content []byte, out *strings.Builder) {
+	var pending []string
+	for i := 0; i < len(content); {
+		switch ch := content[i]; {
+		case ch == '(':
+			s, next := readPDFLiteral(content, i)
+			pending = append(pending, s)
+			i = next
+		case ch == '<' && i+1 < len(content) && content[i+1] != '<':
+			s, next := readPDFHex(content, i)
+			pending = append(pending, s)
+			i = next
+		case ch == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case isPDFRegular(ch):
+			start := i
+			for i < len(content) && isPDFRegular(content[i]) {
+				i++
+			}
+			switch string(content[start:i]) {
+			case "Tj", "TJ":
+				out.WriteString(strings.Join(pending, ""))
+			case "'", "\"":
+				out.WriteString("\n" + strings.Join(pending, ""))
+			case "T*", "Td", "TD", "ET":
+				out.WriteString("\n")
+			}
+			if !isPDFNumber(content[start:i]) {
+				pending = pending[:0]
+			}
+		default:
+			i++
+		}
+	}
+}
+
+func isPDFRegular(ch byte) bool {
+	return !strings.ContainsRune(" \t\r\n\f\x00()<>[]{}/%", rune(ch))
+}
+
+// isPDFNumber reports whether a token is a number, such as the kerning
+// adjustments between the strings of a TJ array
+func isPDFNumber(token []byte) bool {
+	for _, ch := range token {
+		if !(ch >= '0' && ch <= '9' || ch == '.' || ch == '-' || ch == '+') {
+			return false
+		}
+	}
+	return true
+}
+
+func readPDFLiteral(content []byte, i int) (string, int) {
+	var s []byte
+	depth := 0
+	for i++; i < len(content); i++ {
+		ch := content[i]
+		switch {
+		case ch == '\\' && i+1 < len(content):
+			i++
+			switch esc := content[i]; esc {
+			case 'n':
+				s = append(s, '\n')
+			case 'r', 't', 'b', 'f':
+				s = append(s, ' ')
+			case '\r', '\n':
+				// Line continuation
+			default:
+				if esc >= '0' && esc <= '7' {
+					n, digits := 0, 0
+					for digits < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7' {
+						n = n*8 + int(content[i]-'0')
+						i++
+						digits++
+					}
+					i--
+					s = append(s, byte(n))
+				} else {
+					s = append(s, esc)
+				}
+			}
+		case ch == '(':
+			depth++
+			s = append(s, ch)
+		case ch == ')':
+			if depth == 0 {
+				return pdfBytesToString(s), i + 1
+			}
+			depth--
+			s = append(s, ch)
+		default:
+			s = append(s, ch)
+		}
+	}
+	return pdfBytesToString(s), i
+}
+
+func readPDFHex(content []byte, i int) (string, int) {
+	end := bytes.IndexByte(content[i:], '>')
+	if end < 0 {
+		return "", len(content)
+	}
+	var digits []byte
+	for _, ch := range content[i+1 : i+end] {
+		if strings.ContainsRune("0123456789abcdefABCDEF", rune(ch)) {
+			digits = append(digits, ch)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	s := make([]byte, len(digits)/2)
+	for j := range s {
+		s[j] = hexValue(digits[2*j])<<4 | hexValue(digits[2*j+1])
+	}
+	return pdfBytesToString(s), i + end + 1
+}
+
+func hexValue(ch byte) byte {
+	switch {
+	case ch >= 'a':
+		return ch - 'a' + 10
+	case ch >= 'A':
+		return ch - 'A' + 10
+	default:
+		return ch - '0'
+	}
+}
+
+// pdfBytesToString decodes a PDF string as UTF-16 when it carries a byte
+// order mark and as Latin-1 otherwise
+func pdfBytesToString(s []byte) string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		var b strings.Builder
+		for i := 2; i+1 < len(s); i += 2 {
+			b.WriteRune(rune(s[i])<<8 | rune(s[i+1]))
+		}
+		return b.String()
+	}
+	runes := make([]rune, len(s))
+	for i, ch := range s {
+		runes[i] = rune(ch)
+	}
+	return string(runes)
+}
+
+// extractDOCXText reads the paragraphs of the main document part
+func extractDOCXText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range archive.File {
+		if entry.Name != "word/document.xml" {
+			continue
+		}
+		r, err := entry.Open()
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+
+		var out strings.Builder
+		decoder := xml.NewDecoder(io.LimitReader(r, 16*maxExtractedText))
+		inText := false
+		for {
+			token, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return strings.TrimSpace(out.String()), err
+			}
+			switch t := token.(type) {
+			case xml.StartElement:
+				switch t.Name.Local {
+				case "t":
+					inText = true
+				case "tab", "br":
+					out.WriteString(" ")
+				}
+			case xml.EndElement:
+				switch t.Name.Local {
+				case "t":
+					inText = false
+				case "p":
+					out.WriteString("\n")
+				}
+			case xml.CharData:
+				if inText {
+					out.Write(t)
+				}
+			}
+		}
+		return strings.TrimSpace(out.String()), nil
+	}
+	return "", nil
+}
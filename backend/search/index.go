@@ -0,0 +1,251 @@
+package search
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// metadataWeight makes a match in a document's name, type or owner count for
+// more than one in its contents
+const metadataWeight = 3
+
+const snippetRadius = 80
+
+// Hit is one search result
+type Hit struct {
+	DocumentID string  `json:"documentId"`
+	Score      float64 `json:"score"`
+	Snippet    string  `json:"snippet"`
+}
+
+type indexedDocument struct {
+	text   string         // Extracted contents, used for snippets
+	joined string         // Normalized content and metadata terms, for phrases
+	terms  map[string]int // Weighted term frequencies
+	length int
+}
+
+// Index is an in-memory inverted index over document contents and metadata.
+// It is safe for concurrent use, as uploads and the disposal job update it
+// while searches run.
+type Index struct {
+	mu        sync.RWMutex
+	postings  map[string]map[string]int // map[term]map[documentID]weighted frequency
+	documents map[string]*indexedDocument
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings:  make(map[string]map[string]int),
+		documents: make(map[string]*indexedDocument),
+	}
+}
+
+// Documents indexes the current version of every document
+var Documents = NewIndex()
+
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits text into lower-cased words. Dots and commas between
+// digits are kept so that clause numbers such as 7.2 stay one term.
+func tokenize(text string) []token {
+	tokens := make([]token, 0)
+	runes := []rune(text)
+	offsets := make([]int, len(runes)+1)
+	for i, offset := 0, 0; i < len(runes); i++ {
+		offsets[i] = offset
+		offset += len(string(runes[i]))
+		offsets[i+1] = offset
+	}
+
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsDigit(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) {
+			r := runes[i]
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				i++
+				continue
+			}
+			if (r == '.' || r == ',') && i > start && i+1 < len(runes) &&
+				unicode.IsDigit(runes[i-1]) && unicode.IsDigit(runes[i+1]) {
+				i++
+				continue
+			}
+			break
+		}
+		tokens = append(tokens, token{
+			term:  strings.ToLower(string(runes[start:i])),
+			start: offsets[start],
+			end:   offsets[i],
+		})
+	}
+	return tokens
+}
+
+func joinTerms(tokens []token) string {
+	terms := make([]string, len(tokens))
+	for i, t := range tokens {
+		terms[i] = t.term
+	}
+	return strings.Join(terms, " ")
+}
+
+// Add indexes a document, replacing what was indexed for it before
+func (idx *Index) Add(documentID, text string, metadata ...string) {
+
+	doc := &indexedDocument{text: text, terms: make(map[string]int)}
+	content := tokenize(text)
+	for _, t := range content {
+		doc.terms[t.term]++
+	}
+	joined := []string{joinTerms(content)}
+	for _, field := range metadata {
+		tokens := tokenize(field)
+		for _, t := range tokens {
+			doc.terms[t.term] += metadataWeight
+		}
+		joined = append(joined, joinTerms(tokens))
+	}
+	doc.joined = " " + strings.Join(joined, " | ") + " "
+	doc.length = len(content)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(documentID)
+	idx.documents[documentID] = doc
+	for term, frequency := range doc.terms {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[string]int)
+		}
+		idx.postings[term][documentID] = frequency
+	}
+}
+
+func (idx *Index) Remove(documentID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(documentID)
+}
+
+func (idx *Index) remove(documentID string) {
+	doc, exists := idx.documents[documentID]
+	if !exists {
+		return
+	}
+	for term := range doc.terms {
+		delete(idx.postings[term], documentID)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.documents, documentID)
+}
+
+var quotedPhrase = regexp.MustCompile(`"([^"]*)"`)
+
+// Search finds the documents containing every term of the query, and every
+// "quoted phrase" in order, among those allowed by the filter. Hits are
+// ranked by TF-IDF, best first, and at most limit are returned.
+func (idx *Index) Search(query string, allowed func(documentID string) bool, limit int) []Hit {
+	phrases := make([]string, 0)
+	for _, match := range quotedPhrase.FindAllStringSubmatch(query, -1) {
+		if phrase := joinTerms(tokenize(match[1])); phrase != "" {
+			phrases = append(phrases, " "+phrase+" ")
+		}
+	}
+	queryTokens := tokenize(query)
+	if len(queryTokens) == 0 {
+		return []Hit{}
+	}
+	terms := make(map[string]bool)
+	for _, t := range queryTokens {
+		terms[t.term] = true
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	// Start from the rarest term's postings
+	var rarest map[string]int
+	for term := range terms {
+		postings := idx.postings[term]
+		if rarest == nil || len(postings) < len(rarest) {
+			rarest = postings
+		}
+	}
+
+	hits := make([]Hit, 0)
+	total := float64(len(idx.documents))
+	for documentID := range rarest {
+		if !allowed(documentID) {
+			continue
+		}
+		doc := idx.documents[documentID]
+
+		score, matched := 0.0, true
+		for term := range terms {
+			frequency := idx.postings[term][documentID]
+			if frequency == 0 {
+				matched = false
+				break
+			}
+			idf := math.Log(1 + total/float64(len(idx.postings[term])))
+			score += float64(frequency) / math.Sqrt(float64(doc.length+1)) * idf
+		}
+		for _, phrase := range phrases {
+			matched = matched && strings.Contains(doc.joined, phrase)
+		}
+		if !matched {
+			continue
+		}
+		hits = append(hits, Hit{DocumentID: documentID, Score: score, Snippet: snippet(doc.text, terms)})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].DocumentID < hits[j].DocumentID
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// snippet returns the text around the first query term in a document's
+// contents, or its opening when only the metadata matched
+func snippet(text string, terms map[string]bool) string {
+	start, end := 0, 0
+	for _, t := range tokenize(text) {
+		if terms[t.term] {
+			start, end = t.start, t.end
+			break
+		}
+	}
+
+	from, to := start-snippetRadius, end+snippetRadius
+	prefix, suffix := "…", "…"
+	if from <= 0 {
+		from, prefix = 0, ""
+	} else if space := strings.IndexAny(text[from:start], " \n"); space >= 0 {
+		from += space + 1
+	}
+	if to >= len(text) {
+		to, suffix = len(text), ""
+	} else if space := strings.LastIndexAny(text[end:to], " \n"); space >= 0 {
+		to = end + space
+	}
+	return prefix + strings.Join(strings.Fields(strings.ToValidUTF8(text[from:to], "")), " ") + suffix
+}
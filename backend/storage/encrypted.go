@@ -0,0 +1,329 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// envelopeSuffix names the object holding the wrapped data key of the
+	// object stored under the same key without the suffix
+	envelopeSuffix = ".dek"
+	// encryptionChunkSize is the plaintext size of each sealed chunk. Files
+	// are sealed in chunks so that range requests only decrypt what they read.
+	encryptionChunkSize = 64 << 10
+	gcmOverhead         = 16
+)
+
+// envelope is stored next to each encrypted object. Rotating master keys
+// rewrites envelopes only, never the encrypted files.
+type envelope struct {
+	KeyID      string `json:"keyId"`
+	WrappedKey []byte `json:"wrappedKey"`
+	ChunkSize  int    `json:"chunkSize"`
+}
+
+// EncryptedStore encrypts objects with a fresh data key each before handing
+// them to another backend, and decrypts them again when opened. Objects
+// written before encryption was enabled have no envelope and are read as
+// they are.
+type EncryptedStore struct {
+	inner BlobStore
+	keys  KeyWrapper
+}
+
+func NewEncryptedStore(inner BlobStore, keys KeyWrapper) *EncryptedStore {
+	return &EncryptedStore{inner: inner, keys: keys}
+}
+
+func newChunkCipher(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce derives the nonce of a chunk from its position. Data keys are
+// never reused, so positions are unique nonces, and flagging the final chunk
+// makes truncated files fail to decrypt.
+func chunkNonce(index int64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce, uint64(index))
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+func (s *EncryptedStore) readEnvelope(key string) (*envelope, error) {
+	obj, err := s.inner.Open(key + envelopeSuffix)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	var env envelope
+	if err := json.NewDecoder(obj).Decode(&env); err != nil {
+		return nil, fmt.Errorf("read envelope of %s: %w", key, err)
+	}
+	return &env, nil
+}
+
+func (s *EncryptedStore) writeEnvelope(key string, env *envelope) error {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return s.inner.Put(key+envelopeSuffix, bytes.NewReader(data), int64(len(data)))
+}
+
+func (s *EncryptedStore) Put(key string, r io.Reader, size int64) error {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return err
+	}
+	aead, err := newChunkCipher(dataKey)
+	if err != nil {
+		return err
+	}
+	keyID, wrapped, err := s.keys.Wrap(dataKey, []byte(key))
+	if err != nil {
+		return err
+	}
+
+	// The envelope goes first so that the file is never readable without it
+	env := &envelope{KeyID: keyID, WrappedKey: wrapped, ChunkSize: encryptionChunkSize}
+	if err := s.writeEnvelope(key, env); err != nil {
+		return err
+	}
+
+	sealedSize := int64(-1)
+	if size >= 0 {
+		chunks := (size + encryptionChunkSize - 1) / encryptionChunkSize
+		if chunks == 0 {
+			chunks = 1
+		}
+		sealedSize = size + chunks*gcmOverhead
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(sealChunks(pw, r, aead, encryptionChunkSize))
+	}()
+	err = s.inner.Put(key, pr, sealedSize)
+	pr.Close()
+	if err != nil {
+		s.inner.Delete(key + envelopeSuffix)
+		return err
+	}
+	return nil
+}
+
+// readChunk fills buf as far as the reader allows
+func readChunk(r io.Reader, buf []byte) (int, error) {
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return n, err
+}
+
+// sealChunks encrypts r chunk by chunk, reading one chunk ahead to know which
+// chunk is the last
+func sealChunks(w io.Writer, r io.Reader, aead cipher.AEAD, chunkSize int) error {
+	current, next := make([]byte, chunkSize), make([]byte, chunkSize)
+	n, err := readChunk(r, current)
+	if err != nil {
+		return err
+	}
+	for index := int64(0); ; index++ {
+		m, err := readChunk(r, next)
+		if err != nil {
+			return err
+		}
+		last := m == 0
+		if _, err := w.Write(aead.Seal(nil, chunkNonce(index, last), current[:n], nil)); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		current, next, n = next, current, m
+	}
+}
+
+func (s *EncryptedStore) Open(key string) (Object, error) {
+	env, err := s.readEnvelope(key)
+	if errors.Is(err, ErrNotFound) {
+		return s.inner.Open(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := s.keys.Unwrap(env.KeyID, env.WrappedKey, []byte(key))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newChunkCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := s.inner.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	sealedChunk := int64(env.ChunkSize + gcmOverhead)
+	chunks := (obj.Size() + sealedChunk - 1) / sealedChunk
+	if env.ChunkSize <= 0 || chunks == 0 || obj.Size()-(chunks-1)*sealedChunk < gcmOverhead {
+		obj.Close()
+		return nil, fmt.Errorf("Encrypted object %s is truncated", key)
+	}
+
+	return &encryptedObject{
+		inner:     obj,
+		aead:      aead,
+		chunkSize: int64(env.ChunkSize),
+		chunks:    chunks,
+		size:      obj.Size() - chunks*gcmOverhead,
+		loaded:    -1,
+	}, nil
+}
+
+func (s *EncryptedStore) Delete(key string) error {
+	if err := s.inner.Delete(key); err != nil {
+		return err
+	}
+	return s.inner.Delete(key + envelopeSuffix)
+}
+
+// List hides the envelopes stored alongside the objects
+func (s *EncryptedStore) List(prefix string) ([]string, error) {
+	keys, err := s.inner.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !strings.HasSuffix(key, envelopeSuffix) {
+			objects = append(objects, key)
+		}
+	}
+	return objects, nil
+}
+
+// Rewrap re-encrypts every data key that is not wrapped by the active master
+// key. The encrypted files themselves are left untouched. It returns the
+// number of data keys rewrapped.
+func (s *EncryptedStore) Rewrap() (int, error) {
+	keys, err := s.inner.List("")
+	if err != nil {
+		return 0, err
+	}
+
+	rewrapped := 0
+	for _, name := range keys {
+		key, isEnvelope := strings.CutSuffix(name, envelopeSuffix)
+		if !isEnvelope {
+			continue
+		}
+		env, err := s.readEnvelope(key)
+		if err != nil {
+			return rewrapped, err
+		}
+		if env.KeyID == s.keys.ActiveKeyID() {
+			continue
+		}
+
+		dataKey, err := s.keys.Unwrap(env.KeyID, env.WrappedKey, []byte(key))
+		if err != nil {
+			return rewrapped, fmt.Errorf("unwrap %s: %w", key, err)
+		}
+		env.KeyID, env.WrappedKey, err = s.keys.Wrap(dataKey, []byte(key))
+		if err != nil {
+			return rewrapped, fmt.Errorf("wrap %s: %w", key, err)
+		}
+		if err := s.writeEnvelope(key, env); err != nil {
+			return rewrapped, fmt.Errorf("write envelope of %s: %w", key, err)
+		}
+		rewrapped++
+	}
+	return rewrapped, nil
+}
+
+// encryptedObject decrypts the chunk under the read offset on demand
+type encryptedObject struct {
+	inner     Object
+	aead      cipher.AEAD
+	chunkSize int64
+	chunks    int64
+	size      int64
+	offset    int64
+	loaded    int64
+	plain     []byte
+}
+
+func (o *encryptedObject) Size() int64        { return o.size }
+func (o *encryptedObject) ModTime() time.Time { return o.inner.ModTime() }
+func (o *encryptedObject) Close() error       { return o.inner.Close() }
+
+func (o *encryptedObject) load(index int64) error {
+	sealedChunk := o.chunkSize + gcmOverhead
+	if _, err := o.inner.Seek(index*sealedChunk, io.SeekStart); err != nil {
+		return err
+	}
+	sealed := make([]byte, sealedChunk)
+	n, err := readChunk(o.inner, sealed)
+	if err != nil {
+		return err
+	}
+	plain, err := o.aead.Open(nil, chunkNonce(index, index == o.chunks-1), sealed[:n], nil)
+	if err != nil {
+		return errors.New("Encrypted object failed authentication")
+	}
+	o.loaded, o.plain = index, plain
+	return nil
+}
+
+func (o *encryptedObject) Read(p []byte) (int, error) {
+	if o.offset >= o.size {
+		return 0, io.EOF
+	}
+	index := o.offset / o.chunkSize
+	if index != o.loaded {
+		if err := o.load(index); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, o.plain[o.offset-index*o.chunkSize:])
+	o.offset += int64(n)
+	return n, nil
+}
+
+func (o *encryptedObject) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		offset += o.size
+	default:
+		return 0, errors.New("Invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("Negative position")
+	}
+	o.offset = offset
+	return offset, nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeyWrapper protects data keys with master keys that never leave it. The
+// KeyRing below keeps master keys from the configuration and stands in for a
+// KMS, which can be plugged in by implementing this interface.
+type KeyWrapper interface {
+	// Wrap encrypts a data key under the active master key. The context is
+	// authenticated and must be passed again to unwrap.
+	Wrap(dataKey, context []byte) (keyID string, wrapped []byte, err error)
+	Unwrap(keyID string, wrapped, context []byte) ([]byte, error)
+	ActiveKeyID() string
+}
+
+// KeyRing holds the active master key and any retired keys still needed to
+// unwrap data keys that have not been rotated yet
+type KeyRing struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// ParseKeyRing reads master keys written as <id>:<base64 of 32 bytes>. The
+// retired keys are a comma-separated list in the same format.
+func ParseKeyRing(active, retired string) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string]cipher.AEAD)}
+	specs := []string{active}
+	if retired != "" {
+		specs = append(specs, strings.Split(retired, ",")...)
+	}
+
+	for i, spec := range specs {
+		id, encoded, found := strings.Cut(strings.TrimSpace(spec), ":")
+		if !found || id == "" {
+			return nil, errors.New("Master keys must be written as <id>:<base64 key>")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("Master key %s must be 32 bytes encoded as base64", id)
+		}
+		if _, exists := ring.keys[id]; exists {
+			return nil, fmt.Errorf("Master key %s is listed twice", id)
+		}
+		block, _ := aes.NewCipher(key)
+		aead, _ := cipher.NewGCM(block)
+		ring.keys[id] = aead
+		if i == 0 {
+			ring.active = id
+		}
+	}
+	return ring, nil
+}
+
+func (r *KeyRing) ActiveKeyID() string { return r.active }
+
+func (r *KeyRing) Wrap(dataKey, context []byte) (string, []byte, error) {
+	aead := r.keys[r.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return r.active, aead.Seal(nonce, nonce, dataKey, context), nil
+}
+
+func (r *KeyRing) Unwrap(keyID string, wrapped, context []byte) ([]byte, error) {
+	aead, exists := r.keys[keyID]
+	if !exists {
+		return nil, fmt.Errorf("Master key %s is not configured", keyID)
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("Wrapped data key is truncated")
+	}
+	nonce, sealed := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, context)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unwrap data key with master key %s", keyID)
+	}
+	return dataKey, nil
+}
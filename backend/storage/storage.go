@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"vendor-management/config"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("Object not found")
+
+// Object is an open stored file. It supports seeking so that it can be
+// served with range requests.
+type Object interface {
+	io.ReadSeekCloser
+	Size() int64
+	ModTime() time.Time
+}
+
+// BlobStore stores files under slash-separated keys such as
+// documents/<ownerID>/<fileID>.pdf
+type BlobStore interface {
+	// Put stores the contents of r under the key, replacing any existing
+	// object. size is the length of r, or -1 if unknown.
+	Put(key string, r io.Reader, size int64) error
+	Open(key string) (Object, error)
+	// Delete removes the object. Deleting a missing key is not an error.
+	Delete(key string) error
+	// List returns the keys starting with the prefix
+	List(prefix string) ([]string, error)
+}
+
+// Store is the backend selected by the STORAGE_BACKEND setting
+var Store BlobStore
+
+func init() {
+	store, err := New(config.StorageBackend)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize %s storage: %v", config.StorageBackend, err))
+	}
+	Store = store
+}
+
+// New creates the named storage backend from the configuration, encrypting
+// it when a master key is configured
+func New(backend string) (BlobStore, error) {
+	store, err := newBackend(backend)
+	if err != nil || config.EncryptionKey == "" {
+		return store, err
+	}
+	keys, err := ParseKeyRing(config.EncryptionKey, config.EncryptionRetiredKeys)
+	if err != nil {
+		return nil, err
+	}
+	return NewEncryptedStore(store, keys), nil
+}
+
+func newBackend(backend string) (BlobStore, error) {
+	switch backend {
+	case "local":
+		return NewLocalStore(config.StorageLocalRoot)
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:  config.S3Endpoint,
+			Region:    config.S3Region,
+			Bucket:    config.S3Bucket,
+			AccessKey: config.S3AccessKey,
+			SecretKey: config.S3SecretKey,
+			PathStyle: config.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("Unknown storage backend: %s", backend)
+	}
+}
+
+// Migrate copies every object from one backend to another, optionally
+// deleting each source object once it has been copied. It returns the number
+// of objects copied.
+func Migrate(from, to BlobStore, deleteSource bool) (int, error) {
+	keys, err := from.List("")
+	if err != nil {
+		return 0, err
+	}
+
+	copied := 0
+	for _, key := range keys {
+		obj, err := from.Open(key)
+		if err != nil {
+			return copied, fmt.Errorf("open %s: %w", key, err)
+		}
+		err = to.Put(key, obj, obj.Size())
+		obj.Close()
+		if err != nil {
+			return copied, fmt.Errorf("copy %s: %w", key, err)
+		}
+		if deleteSource {
+			if err := from.Delete(key); err != nil {
+				return copied, fmt.Errorf("delete %s: %w", key, err)
+			}
+		}
+		copied++
+	}
+	return copied, nil
+}
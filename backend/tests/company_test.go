@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCompanyRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/companies", handlers.CreateCompany)
+		admin.PUT("/companies/:id", handlers.UpdateCompany)
+		admin.GET("/companies/:id/workers", handlers.ListCompanyWorkers)
+		admin.GET("/companies/:id/summary", handlers.GetCompanySummary)
+		admin.POST("/vendors", handlers.CreateVendor)
+	}
+	return r
+}
+
+func TestCompanyWorkersAndSummary(t *testing.T) {
+	router := setupCompanyRouter()
+	token := loginAdmin(t, router)
+
+	company := map[string]interface{}{
+		"legalName": "Globex Staffing Pvt Ltd",
+		"taxIds":    []map[string]string{{"type": "GST", "value": "29ABCDE1234F1Z5"}},
+		"contacts":  []map[string]string{{"name": "Hank", "email": "hank@globex.test"}},
+	}
+	w := doJSON(router, "POST", "/api/admin/companies", token, company)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.Company
+	json.Unmarshal(w.Body.Bytes(), &created)
+
+	w = doJSON(router, "POST", "/api/admin/companies", token, map[string]interface{}{
+		"legalName": "Broken Co",
+		"taxIds":    []map[string]string{{"type": "GST"}},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Workers reference the company instead of repeating its name
+	w = doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyId":   created.ID,
+		"joiningDate": "2024-04-01",
+		"department":  "IT",
+		"projectName": "Helpdesk",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var worker models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &worker)
+	assert.Equal(t, "Globex Staffing Pvt Ltd", worker.CompanyName)
+
+	w = doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyId":   "missing",
+		"joiningDate": "2024-04-01",
+		"department":  "IT",
+		"projectName": "Helpdesk",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	models.AttendanceRecords[worker.ID] = []*models.Attendance{
+		{ID: "a1", VendorID: worker.ID, PresentDay: 1},
+		{ID: "a2", VendorID: worker.ID, PresentDay: 0.5},
+	}
+
+	w = doJSON(router, "GET", "/api/admin/companies/"+created.ID+"/summary", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var summary struct {
+		Headcount struct {
+			Total  int `json:"total"`
+			Active int `json:"active"`
+		} `json:"headcount"`
+		Attendance struct {
+			PresentDays float64 `json:"presentDays"`
+		} `json:"attendance"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &summary)
+	assert.Equal(t, 1, summary.Headcount.Total)
+	assert.Equal(t, 1, summary.Headcount.Active)
+	assert.Equal(t, 1.5, summary.Attendance.PresentDays)
+
+	// Renaming the company is mirrored on its workers
+	company["legalName"] = "Globex Workforce Ltd"
+	w = doJSON(router, "PUT", "/api/admin/companies/"+created.ID, token, company)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Globex Workforce Ltd", models.Vendors[worker.ID].CompanyName)
+
+	w = doJSON(router, "GET", "/api/admin/companies/"+created.ID+"/workers", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), worker.ID)
+}
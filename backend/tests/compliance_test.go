@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+	"vendor-management/storage"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupComplianceRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	api.GET("/my-documents", handlers.GetMyDocuments)
+	api.POST("/my-documents", handlers.UploadMyDocument)
+	api.GET("/my-documents/search", handlers.SearchMyDocuments)
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.GET("/vendors/:id/compliance", handlers.GetVendorCompliance)
+		admin.POST("/documents", handlers.UploadDocument)
+		admin.GET("/compliance", handlers.ListCompliance)
+		admin.POST("/compliance/rules", handlers.CreateComplianceRule)
+		admin.DELETE("/compliance/rules/:id", handlers.DeleteComplianceRule)
+	}
+	return r
+}
+
+// uploadDocument posts a multipart document upload and removes the stored
+// file when the test finishes
+func uploadDocument(t *testing.T, router *gin.Engine, path, token string, fields map[string]string, filename string, content []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for key, value := range fields {
+		form.WriteField(key, value)
+	}
+	part, _ := form.CreateFormFile("file", filename)
+	part.Write(content)
+	form.Close()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+
+	var doc models.Document
+	if json.Unmarshal(w.Body.Bytes(), &doc) == nil && doc.StorageKey != "" {
+		t.Cleanup(func() { storage.Store.Delete(doc.StorageKey) })
+	}
+	return w
+}
+
+func TestComplianceSuspendsAndReinstates(t *testing.T) {
+	router := setupComplianceRouter()
+	token := loginAdmin(t, router)
+	defer func() {
+		models.ComplianceRules = make(map[string]*models.ComplianceRule)
+		models.ComplianceStatuses = make(map[string]*models.ComplianceStatus)
+	}()
+
+	w := doJSON(router, "POST", "/api/admin/compliance/rules", token, map[string]interface{}{
+		"vendorType": "contractor", "documentType": "insurance", "critical": true,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doJSON(router, "POST", "/api/admin/compliance/rules", token, map[string]interface{}{
+		"vendorType": "contractor", "documentType": "nda",
+	})
+	var ndaRule models.ComplianceRule
+	json.Unmarshal(w.Body.Bytes(), &ndaRule)
+
+	w = doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Compliance Co",
+		"type":        "contractor",
+		"joiningDate": "2024-01-01",
+		"department":  "Legal",
+		"projectName": "Audit",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	defer func() { models.Vendors[vendor.ID].Status = "inactive" }()
+
+	// Other tests' vendors may be suspended while the hooks are registered
+	events := make([]string, 0)
+	t.Cleanup(utils.OnVendorSuspended(func(v *models.Vendor, status *models.ComplianceStatus) {
+		if v.ID == vendor.ID {
+			events = append(events, "suspended:"+v.Status+":"+string(status.State))
+		}
+	}))
+	t.Cleanup(utils.OnVendorReinstated(func(v *models.Vendor, status *models.ComplianceStatus) {
+		if v.ID == vendor.ID {
+			events = append(events, "reinstated:"+v.Status+":"+string(status.State))
+		}
+	}))
+
+	user := &models.User{ID: "compliance-vendor-user", Name: "Compliance Vendor", Email: "compliance@vendor.com", Role: models.VendorRole}
+	models.Users[user.ID] = user
+	defer delete(models.Users, user.ID)
+	models.Vendors[vendor.ID].UserID = user.ID
+	vendorToken, _ := middleware.GenerateToken(user.ID, string(user.Role))
+
+	// An expired insurance certificate is a critical lapse
+	w = uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+		"vendorId": vendor.ID, "type": "insurance", "validFrom": "2023-01-01", "expiresAt": "2023-12-31",
+	}, "insurance.pdf", []byte("%PDF-1.4 insurance 2023"))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	utils.CheckCompliance()
+	assert.Equal(t, "suspended", models.Vendors[vendor.ID].Status)
+	assert.Equal(t, []string{"suspended:suspended:" + string(models.NonCompliant)}, events)
+
+	// A suspended vendor is refused, except for seeing and uploading its
+	// documents
+	w = doJSON(router, "GET", "/api/my-documents/search?q=insurance", vendorToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = doJSON(router, "GET", "/api/my-documents", vendorToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = uploadDocument(t, router, "/api/my-documents", vendorToken, map[string]string{"type": "nda"}, "my-nda.pdf", []byte("%PDF-1.4 my nda"))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var pending models.Document
+	json.Unmarshal(w.Body.Bytes(), &pending)
+	defer delete(models.Documents, pending.ID)
+
+	w = doJSON(router, "GET", "/api/admin/compliance", token, nil)
+	var listed []struct {
+		VendorID   string                  `json:"vendorId"`
+		Compliance models.ComplianceStatus `json:"compliance"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &listed)
+	found := false
+	for _, entry := range listed {
+		if entry.VendorID == vendor.ID {
+			found = true
+			assert.Equal(t, []string{"insurance"}, entry.Compliance.Expired)
+			assert.Equal(t, []string{"nda"}, entry.Compliance.Missing)
+		}
+	}
+	assert.True(t, found)
+
+	// Uploading a current certificate lifts the suspension straight away,
+	// while the missing NDA keeps the vendor non-compliant
+	expiresAt := time.Now().AddDate(0, 0, 10).Format("2006-01-02")
+	uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+		"vendorId": vendor.ID, "type": "insurance", "validFrom": "2024-01-01", "expiresAt": expiresAt,
+	}, "insurance-2024.pdf", []byte("%PDF-1.4 insurance 2024"))
+	assert.Equal(t, "active", models.Vendors[vendor.ID].Status)
+	assert.Equal(t, models.NonCompliant, models.ComplianceStatuses[vendor.ID].State)
+	assert.Equal(t, []string{
+		"suspended:suspended:" + string(models.NonCompliant),
+		"reinstated:active:" + string(models.NonCompliant),
+	}, events)
+	w = doJSON(router, "GET", "/api/my-documents/search?q=insurance", vendorToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"insurance"}, models.ComplianceStatuses[vendor.ID].Expiring)
+
+	w = uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+		"vendorId": vendor.ID, "type": "nda", "validFrom": "2024-02-01", "expiresAt": "2024-01-01",
+	}, "nda.pdf", []byte("%PDF-1.4 nda"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Once the NDA is no longer required only the expiring insurance remains
+	w = doJSON(router, "DELETE", "/api/admin/compliance/rules/"+ndaRule.ID, token, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doJSON(router, "DELETE", "/api/admin/compliance/rules/"+ndaRule.ID, token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	utils.CheckCompliance()
+	assert.Equal(t, models.Expiring, models.ComplianceStatuses[vendor.ID].State)
+}
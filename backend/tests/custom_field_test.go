@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCustomFieldRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/custom-fields", handlers.CreateCustomField)
+		admin.DELETE("/custom-fields/:id", handlers.DeleteCustomField)
+		admin.POST("/assets", handlers.CreateAsset)
+		admin.GET("/assets", handlers.ListAssets)
+	}
+	return r
+}
+
+func TestAssetCustomFields(t *testing.T) {
+	router := setupCustomFieldRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/custom-fields", token, map[string]interface{}{
+		"entity":   "asset",
+		"key":      "ramGb",
+		"label":    "RAM (GB)",
+		"type":     "number",
+		"required": true,
+		"min":      4,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var ram models.CustomFieldDefinition
+	json.Unmarshal(w.Body.Bytes(), &ram)
+	defer delete(models.CustomFields, ram.ID)
+
+	w = doJSON(router, "POST", "/api/admin/custom-fields", token, map[string]interface{}{
+		"entity": "asset",
+		"key":    "tier",
+		"label":  "Tier",
+		"type":   "enum",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "enum fields need options")
+
+	// Values are validated against the definition
+	for _, fields := range []map[string]interface{}{
+		{},                           // missing required field
+		{"ramGb": "sixteen"},         // wrong type
+		{"ramGb": 2},                 // below minimum
+		{"ramGb": 16, "colour": "x"}, // undefined field
+	} {
+		w = doJSON(router, "POST", "/api/admin/assets", token, map[string]interface{}{
+			"name":         "Laptop",
+			"customFields": fields,
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%v", fields)
+	}
+
+	w = doJSON(router, "POST", "/api/admin/assets", token, map[string]interface{}{
+		"name":         "CF Laptop",
+		"customFields": map[string]interface{}{"ramGb": 32},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var asset models.Asset
+	json.Unmarshal(w.Body.Bytes(), &asset)
+	assert.Equal(t, 32.0, asset.CustomFields["ramGb"])
+
+	// Custom fields are usable as list filters
+	w = doJSON(router, "GET", "/api/admin/assets?cf.ramGb=32", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), asset.ID)
+	w = doJSON(router, "GET", "/api/admin/assets?cf.ramGb=8", token, nil)
+	assert.NotContains(t, w.Body.String(), asset.ID)
+	w = doJSON(router, "GET", "/api/admin/assets?cf.unknown=1", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Deleting the definition strips stored values
+	w = doJSON(router, "DELETE", "/api/admin/custom-fields/"+ram.ID, token, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.NotContains(t, models.Assets[asset.ID].CustomFields, "ramGb")
+}
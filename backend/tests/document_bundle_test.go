@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+	"vendor-management/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupDocumentBundleRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.POST("/documents", handlers.UploadDocument)
+		admin.GET("/documents/bundle", handlers.ExportDocumentBundle)
+	}
+	return r
+}
+
+func TestDocumentBundle(t *testing.T) {
+	useStubScanner(t)
+	router := setupDocumentBundleRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Bundle Co",
+		"joiningDate": "2024-01-01",
+		"department":  "Legal",
+		"projectName": "Audit",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+
+	upload := func(docType, name, content string) models.Document {
+		w := uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+			"vendorId": vendor.ID, "type": docType,
+		}, name, []byte(content))
+		var doc models.Document
+		json.Unmarshal(w.Body.Bytes(), &doc)
+		return doc
+	}
+	upload("agreement", "contract.pdf", "%PDF-1.4 bundle contract")
+	upload("agreement", "contract.pdf", "%PDF-1.4 bundle contract amended")
+	nda := upload("nda", "nda.pdf", "%PDF-1.4 bundle nda")
+	lost := upload("insurance", "insurance.pdf", "%PDF-1.4 bundle insurance")
+	storage.Store.Delete(lost.StorageKey)
+
+	w = doJSON(router, "GET", "/api/admin/documents/bundle?vendorId="+vendor.ID, token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	files := make(map[string]string)
+	for _, entry := range archive.File {
+		r, _ := entry.Open()
+		data, _ := io.ReadAll(r)
+		r.Close()
+		files[entry.Name] = string(data)
+	}
+
+	folder := "vendors/Bundle Co (" + vendor.ID + ")/"
+	assert.Len(t, files, 5)
+	assert.Equal(t, "%PDF-1.4 bundle nda", files[folder+"nda/nda.pdf"])
+	assert.Contains(t, files, folder+"agreement/contract.pdf")
+	assert.Contains(t, files, folder+"agreement/contract (2).pdf")
+
+	var manifest struct {
+		Documents []struct {
+			Path       string `json:"path"`
+			DocumentID string `json:"documentId"`
+			Checksum   string `json:"checksum"`
+			Status     string `json:"status"`
+		} `json:"documents"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(files["manifest.json"]), &manifest))
+	assert.Len(t, manifest.Documents, 4)
+	for _, entry := range manifest.Documents {
+		switch entry.DocumentID {
+		case nda.ID:
+			assert.Equal(t, nda.Checksum, entry.Checksum)
+			assert.Equal(t, "included", entry.Status)
+		case lost.ID:
+			assert.Equal(t, "missing", entry.Status)
+		}
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader([]byte(files["manifest.csv"]))).ReadAll()
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 5) {
+		assert.Equal(t, "path", rows[0][0])
+	}
+
+	w = doJSON(router, "GET", "/api/admin/documents/bundle?vendorId=missing", token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupDocumentReviewRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	api.GET("/my-documents", handlers.GetMyDocuments)
+	api.POST("/my-documents", handlers.UploadMyDocument)
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.POST("/compliance/rules", handlers.CreateComplianceRule)
+		admin.GET("/documents/pending", handlers.ListPendingDocuments)
+		admin.POST("/documents/:id/approve", handlers.ApproveDocument)
+		admin.POST("/documents/:id/reject", handlers.RejectDocument)
+	}
+	return r
+}
+
+// vendorNotifications returns the notification types sent to a user
+func vendorNotifications(userID string) []string {
+	types := make([]string, 0)
+	for _, notification := range models.Notifications {
+		if notification.UserID == userID {
+			types = append(types, notification.Type)
+		}
+	}
+	return types
+}
+
+func TestVendorDocumentReview(t *testing.T) {
+	router := setupDocumentReviewRouter()
+	adminToken := loginAdmin(t, router)
+	defer func() {
+		models.ComplianceRules = make(map[string]*models.ComplianceRule)
+		models.ComplianceStatuses = make(map[string]*models.ComplianceStatus)
+	}()
+
+	user := &models.User{ID: "review-vendor-user", Name: "Review Vendor", Email: "review@vendor.com", Role: models.VendorRole}
+	models.Users[user.ID] = user
+	defer delete(models.Users, user.ID)
+	vendorToken, _ := middleware.GenerateToken(user.ID, string(user.Role))
+
+	w := doJSON(router, "POST", "/api/admin/vendors", adminToken, map[string]string{
+		"companyName": "Self Service Co",
+		"type":        "contractor",
+		"joiningDate": "2024-01-01",
+		"department":  "Legal",
+		"projectName": "Contracts",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	models.Vendors[vendor.ID].UserID = user.ID
+	defer func() { models.Vendors[vendor.ID].Status = "inactive" }()
+
+	doJSON(router, "POST", "/api/admin/compliance/rules", adminToken, map[string]interface{}{
+		"vendorType": "contractor", "documentType": "insurance", "critical": true,
+	})
+	utils.CheckCompliance()
+	assert.Equal(t, "suspended", models.Vendors[vendor.ID].Status)
+
+	w = uploadDocument(t, router, "/api/my-documents", adminToken, map[string]string{"type": "insurance"},
+		"insurance.pdf", []byte("%PDF-1.4 self service insurance"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// A vendor upload waits for review and does not lift the suspension
+	w = uploadDocument(t, router, "/api/my-documents", vendorToken, map[string]string{"type": "insurance"},
+		"insurance.pdf", []byte("%PDF-1.4 self service insurance"))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var doc models.Document
+	json.Unmarshal(w.Body.Bytes(), &doc)
+	assert.Equal(t, models.DocumentPending, doc.Status)
+	assert.Equal(t, vendor.ID, doc.VendorID)
+	assert.Equal(t, "suspended", models.Vendors[vendor.ID].Status)
+
+	w = doJSON(router, "GET", "/api/admin/documents/pending", adminToken, nil)
+	var pending []models.Document
+	json.Unmarshal(w.Body.Bytes(), &pending)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, doc.ID, pending[0].ID)
+	}
+
+	w = doJSON(router, "POST", "/api/admin/documents/"+doc.ID+"/reject", adminToken, map[string]string{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(router, "POST", "/api/admin/documents/"+doc.ID+"/reject", adminToken, map[string]string{"reason": "Policy number is unreadable"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, vendorNotifications(user.ID), "document_rejected")
+
+	w = doJSON(router, "GET", "/api/my-documents", vendorToken, nil)
+	var mine []models.Document
+	json.Unmarshal(w.Body.Bytes(), &mine)
+	if assert.Len(t, mine, 1) {
+		assert.Equal(t, models.DocumentRejected, mine[0].Status)
+		assert.Equal(t, "Policy number is unreadable", mine[0].RejectionReason)
+	}
+
+	w = doJSON(router, "POST", "/api/admin/documents/"+doc.ID+"/approve", adminToken, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Approving a resubmitted certificate counts it for compliance at once
+	w = uploadDocument(t, router, "/api/my-documents", vendorToken, map[string]string{"type": "insurance"},
+		"insurance-rescan.pdf", []byte("%PDF-1.4 self service insurance rescan"))
+	json.Unmarshal(w.Body.Bytes(), &doc)
+	w = doJSON(router, "POST", "/api/admin/documents/"+doc.ID+"/approve", adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "active", models.Vendors[vendor.ID].Status)
+	assert.Contains(t, vendorNotifications(user.ID), "document_approved")
+}
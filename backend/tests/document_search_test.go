@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+	"vendor-management/search"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupDocumentSearchRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	api.GET("/my-documents/search", handlers.SearchMyDocuments)
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.POST("/documents", handlers.UploadDocument)
+		admin.GET("/documents/search", handlers.SearchDocuments)
+		admin.DELETE("/documents/:id", handlers.DeleteDocument)
+	}
+	return r
+}
+
+// pdfContent builds a single-page PDF showing each line of text, with the
+// content stream optionally Flate-compressed
+func pdfContent(compress bool, lines ...string) []byte {
+	var content bytes.Buffer
+	content.WriteString("BT /F1 12 Tf 72 720 Td ")
+	for _, line := range lines {
+		fmt.Fprintf(&content, "(%s) Tj T* ", line)
+	}
+	content.WriteString("ET")
+
+	stream, filter := content.Bytes(), ""
+	if compress {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		w.Write(stream)
+		w.Close()
+		stream, filter = buf.Bytes(), " /Filter /FlateDecode"
+	}
+	return []byte(fmt.Sprintf("%%PDF-1.4\n4 0 obj\n<< /Length %d%s >>\nstream\n%s\nendstream\nendobj\n%%%%EOF\n",
+		len(stream), filter, stream))
+}
+
+type searchResult struct {
+	Document models.Document `json:"document"`
+	Snippet  string          `json:"snippet"`
+}
+
+func TestDocumentSearch(t *testing.T) {
+	router := setupDocumentSearchRouter()
+	adminToken := loginAdmin(t, router)
+
+	user := &models.User{ID: "search-vendor-user", Name: "Search Vendor", Email: "search@vendor.com", Role: models.VendorRole}
+	models.Users[user.ID] = user
+	defer delete(models.Users, user.ID)
+	vendorToken, _ := middleware.GenerateToken(user.ID, string(user.Role))
+
+	w := doJSON(router, "POST", "/api/admin/vendors", adminToken, map[string]string{
+		"companyName": "Searchable Co",
+		"joiningDate": "2024-01-01",
+		"department":  "Legal",
+		"projectName": "Contracts",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	models.Vendors[vendor.ID].UserID = user.ID
+
+	upload := func(docType, name string, content []byte, adminOnly string) string {
+		w := uploadDocument(t, router, "/api/admin/documents", adminToken, map[string]string{
+			"vendorId": vendor.ID, "type": docType, "adminOnly": adminOnly,
+		}, name, content)
+		var doc models.Document
+		json.Unmarshal(w.Body.Bytes(), &doc)
+		return doc.ID
+	}
+	agreementID := upload("agreement", "master-services.pdf", pdfContent(true,
+		"Master services agreement",
+		"Clause 7.2 Either party may terminate with thirty days notice.",
+		"Clause 8.1 Fees are payable monthly."), "false")
+	ndaID := upload("nda", "nda.docx", docxContent(
+		"Mutual non-disclosure agreement",
+		"Confidential information excludes public knowledge."), "false")
+	checkID := upload("other", "background.pdf", pdfContent(false,
+		"Background check: clause 7.2 of the screening policy applies."), "true")
+
+	find := func(path, token string) []searchResult {
+		w := doJSON(router, "GET", path, token, nil)
+		results := make([]searchResult, 0)
+		json.Unmarshal(w.Body.Bytes(), &results)
+		return results
+	}
+	ids := func(results []searchResult) []string {
+		found := make([]string, len(results))
+		for i, result := range results {
+			found[i] = result.Document.ID
+		}
+		return found
+	}
+
+	results := find("/api/admin/documents/search?q=clause+7.2&vendorId="+vendor.ID, adminToken)
+	assert.ElementsMatch(t, []string{agreementID, checkID}, ids(results))
+	for _, result := range results {
+		if result.Document.ID == agreementID {
+			assert.Contains(t, result.Snippet, "Clause 7.2 Either party may terminate")
+		}
+	}
+
+	results = find("/api/admin/documents/search?q=%22terminate+with+thirty+days%22", adminToken)
+	assert.Equal(t, []string{agreementID}, ids(results))
+	results = find("/api/admin/documents/search?q=%22thirty+terminate%22&vendorId="+vendor.ID, adminToken)
+	assert.Empty(t, results)
+
+	results = find("/api/admin/documents/search?q=confidential+information&vendorId="+vendor.ID, adminToken)
+	assert.Equal(t, []string{ndaID}, ids(results))
+
+	// Metadata is searchable too
+	results = find("/api/admin/documents/search?q=searchable+nda", adminToken)
+	assert.Equal(t, []string{ndaID}, ids(results))
+
+	// Vendors only find their own documents that are not admin-only
+	results = find("/api/my-documents/search?q=clause+7.2", vendorToken)
+	assert.Equal(t, []string{agreementID}, ids(results))
+
+	doJSON(router, "DELETE", "/api/admin/documents/"+agreementID, adminToken, nil)
+	results = find("/api/admin/documents/search?q=clause+7.2&vendorId="+vendor.ID, adminToken)
+	assert.Equal(t, []string{checkID}, ids(results))
+
+	w = doJSON(router, "GET", "/api/admin/documents/search", adminToken, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSearchIndexConcurrentUse(t *testing.T) {
+	index := search.NewIndex()
+	allowAll := func(string) bool { return true }
+
+	// Uploads, disposals and searches touch the index at the same time
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("doc-%d", i)
+			index.Add(id, fmt.Sprintf("Master services agreement clause %d", i), "agreement")
+			index.Search("agreement", allowAll, 100)
+			if i%2 == 0 {
+				index.Remove(id)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, index.Search("agreement", allowAll, 100), 10)
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupDocumentVersionRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.POST("/documents", handlers.UploadDocument)
+		admin.GET("/documents/:id", handlers.GetDocument)
+		admin.GET("/documents/:id/versions", handlers.ListDocumentVersions)
+		admin.POST("/documents/:id/versions", handlers.AddDocumentVersion)
+		admin.GET("/documents/:id/versions/:version", handlers.GetDocumentVersion)
+		admin.PUT("/documents/:id/current", handlers.SetCurrentDocumentVersion)
+	}
+	return r
+}
+
+func TestDocumentVersions(t *testing.T) {
+	useStubScanner(t)
+	router := setupDocumentVersionRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Versioned Co",
+		"joiningDate": "2024-01-01",
+		"department":  "Legal",
+		"projectName": "Contracts",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+
+	w = uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+		"vendorId": vendor.ID, "type": "agreement",
+	}, "agreement.pdf", []byte("%PDF-1.4 original terms"))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var doc models.Document
+	json.Unmarshal(w.Body.Bytes(), &doc)
+	assert.Equal(t, 1, doc.CurrentVersion)
+
+	w = uploadDocument(t, router, "/api/admin/documents/"+doc.ID+"/versions", token, nil,
+		"agreement-signed.pdf", []byte("%PDF-1.4 signed terms"))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &doc)
+	assert.Equal(t, 2, doc.CurrentVersion)
+	assert.Equal(t, "agreement-signed.pdf", doc.Name)
+
+	// The current pointer serves the newest file; older versions stay available
+	w = doJSON(router, "GET", "/api/admin/documents/"+doc.ID, token, nil)
+	assert.Equal(t, "%PDF-1.4 signed terms", w.Body.String())
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	w = doJSON(router, "GET", "/api/admin/documents/"+doc.ID+"/versions/1", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "%PDF-1.4 original terms", w.Body.String())
+	w = doJSON(router, "GET", "/api/admin/documents/"+doc.ID+"/versions/3", token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doJSON(router, "PUT", "/api/admin/documents/"+doc.ID+"/current", token, map[string]int{"version": 1})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(router, "GET", "/api/admin/documents/"+doc.ID, token, nil)
+	assert.Equal(t, "%PDF-1.4 original terms", w.Body.String())
+	assert.Equal(t, 1, models.Vendors[vendor.ID].Documents[0].CurrentVersion)
+}
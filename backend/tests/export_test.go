@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupExportRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.GET("/vendors/export", handlers.ExportVendors)
+		admin.GET("/attendance/export", handlers.ExportAttendance)
+	}
+	return r
+}
+
+// loginAdmin logs in as the default admin user and returns the JWT
+func loginAdmin(t *testing.T, router *gin.Engine) string {
+	body, _ := json.Marshal(map[string]string{
+		"email":    "admin@company.com",
+		"password": "admin",
+		"role":     "admin",
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if !assert.Equal(t, http.StatusOK, w.Code) {
+		t.FailNow()
+	}
+
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp["token"].(string)
+}
+
+// doJSON sends an authenticated request with an optional JSON body
+func doJSON(router *gin.Engine, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestExportVendors(t *testing.T) {
+	router := setupExportRouter()
+	token := loginAdmin(t, router)
+
+	for _, dept := range []string{"Export-Eng", "Export-HR"} {
+		w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+			"companyName": "Acme " + dept,
+			"joiningDate": "2024-01-15",
+			"department":  dept,
+			"projectName": "Exports",
+		})
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	// CSV honours filters and column selection
+	w := doJSON(router, "GET", "/api/admin/vendors/export?department=export-eng&columns=companyName,joiningDate", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "vendors_")
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"companyName", "joiningDate"},
+		{"Acme Export-Eng", "2024-01-15"},
+	}, rows)
+
+	// XLSX is a valid zip containing the worksheet
+	w = doJSON(router, "GET", "/api/admin/vendors/export?format=xlsx&department=Export-HR", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.NoError(t, err)
+	names := make([]string, 0)
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	assert.Contains(t, names, "xl/worksheets/sheet1.xml")
+
+	// Unknown columns and formats are rejected before streaming
+	w = doJSON(router, "GET", "/api/admin/vendors/export?columns=password", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(router, "GET", "/api/admin/vendors/export?format=pdf", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestExportAttendanceDateFilter(t *testing.T) {
+	router := setupExportRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "GET", "/api/admin/attendance/export?startDate=not-a-date", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doJSON(router, "GET", "/api/admin/attendance/export?startDate=2024-01-01&columns=vendorId,presentDay", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"vendorId", "presentDay"}, rows[0])
+}
+
+func TestExportEscapesFormulasInStableOrder(t *testing.T) {
+	router := setupExportRouter()
+	token := loginAdmin(t, router)
+
+	for _, name := range []string{`=HYPERLINK("http://evil.example","Click")`, "+Plus Co", "-Minus Co", "@At Co", "Plain Co"} {
+		w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+			"companyName": name,
+			"joiningDate": "2024-01-15",
+			"department":  "Export-Formula",
+			"projectName": "Exports",
+		})
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	export := func() [][]string {
+		w := doJSON(router, "GET", "/api/admin/vendors/export?department=Export-Formula&columns=id,companyName", token, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		rows, err := csv.NewReader(w.Body).ReadAll()
+		assert.NoError(t, err)
+		return rows[1:]
+	}
+	rows := export()
+
+	names := make([]string, 0)
+	for i, row := range rows {
+		if i > 0 {
+			assert.Less(t, rows[i-1][0], row[0])
+		}
+		names = append(names, row[1])
+	}
+	assert.ElementsMatch(t, []string{`'=HYPERLINK("http://evil.example","Click")`, "'+Plus Co", "'-Minus Co", "'@At Co", "Plain Co"}, names)
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, rows, export())
+	}
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+	"vendor-management/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupIntegrityRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.POST("/documents", handlers.UploadDocument)
+		admin.GET("/documents/integrity", handlers.GetIntegrityReport)
+		admin.POST("/documents/integrity/scan", handlers.RunIntegrityScan)
+		admin.GET("/documents/:id", handlers.GetDocument)
+		admin.POST("/documents/:id/versions", handlers.AddDocumentVersion)
+	}
+	return r
+}
+
+func TestDocumentChecksums(t *testing.T) {
+	useStubScanner(t)
+	router := setupIntegrityRouter()
+	token := loginAdmin(t, router)
+	defer func() { models.LastIntegrityReport = nil }()
+
+	createVendor := func(name string) string {
+		w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+			"companyName": name,
+			"joiningDate": "2024-01-01",
+			"department":  "Legal",
+			"projectName": "Contracts",
+		})
+		var vendor models.Vendor
+		json.Unmarshal(w.Body.Bytes(), &vendor)
+		return vendor.ID
+	}
+	vendorID, otherVendorID := createVendor("Checksum Co"), createVendor("Other Co")
+
+	content := []byte("%PDF-1.4 checksum terms")
+	digest := sha256.Sum256(content)
+	w := uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+		"vendorId": vendorID, "type": "agreement",
+	}, "terms.pdf", content)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var doc models.Document
+	json.Unmarshal(w.Body.Bytes(), &doc)
+	assert.Equal(t, hex.EncodeToString(digest[:]), doc.Checksum)
+
+	// The same file again for the same vendor points at the existing document
+	w = uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+		"vendorId": vendorID, "type": "agreement",
+	}, "terms-copy.pdf", content)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var conflict map[string]string
+	json.Unmarshal(w.Body.Bytes(), &conflict)
+	assert.Equal(t, doc.ID, conflict["documentId"])
+	w = uploadDocument(t, router, "/api/admin/documents/"+doc.ID+"/versions", token, nil, "terms.pdf", content)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+		"vendorId": otherVendorID, "type": "agreement",
+	}, "terms.pdf", content)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = doJSON(router, "GET", "/api/admin/documents/integrity", token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// A file altered in storage is no longer served and shows up in the scan
+	tampered := []byte("%PDF-1.4 altered terms")
+	storage.Store.Put(doc.StorageKey, bytes.NewReader(tampered), int64(len(tampered)))
+	w = doJSON(router, "GET", "/api/admin/documents/"+doc.ID, token, nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	issuesFor := func(body []byte) []models.IntegrityIssue {
+		var report models.IntegrityReport
+		json.Unmarshal(body, &report)
+		issues := make([]models.IntegrityIssue, 0)
+		for _, issue := range report.Issues {
+			if issue.DocumentID == doc.ID {
+				issues = append(issues, issue)
+			}
+		}
+		return issues
+	}
+	w = doJSON(router, "POST", "/api/admin/documents/integrity/scan", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	issues := issuesFor(w.Body.Bytes())
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, models.FileCorrupted, issues[0].Problem)
+	}
+
+	storage.Store.Delete(doc.StorageKey)
+	doJSON(router, "POST", "/api/admin/documents/integrity/scan", token, nil)
+	w = doJSON(router, "GET", "/api/admin/documents/integrity", token, nil)
+	issues = issuesFor(w.Body.Bytes())
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, models.FileMissing, issues[0].Problem)
+	}
+}
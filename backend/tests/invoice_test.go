@@ -0,0 +1,342 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupInvoiceRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/companies", handlers.CreateCompany)
+		admin.POST("/companies/:id/rates", handlers.CreateCompanyRate)
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.GET("/vendors/:id/rates", handlers.ListVendorRates)
+		admin.POST("/vendors/:id/rates", handlers.CreateVendorRate)
+		admin.POST("/tax-rules", handlers.CreateTaxRule)
+		admin.DELETE("/tax-rules/:id", handlers.DeleteTaxRule)
+		admin.POST("/invoices/run", handlers.RunInvoices)
+		admin.GET("/invoices/:id/pdf", handlers.GetInvoicePDF)
+		admin.POST("/invoices/:id/approve", handlers.ApproveInvoice)
+		admin.POST("/invoices/:id/paid", handlers.MarkInvoicePaid)
+		admin.POST("/purchase-orders", handlers.CreatePurchaseOrder)
+		admin.PUT("/purchase-orders/:id", handlers.UpdatePurchaseOrder)
+		admin.GET("/spend", handlers.GetSpendSummary)
+	}
+	return r
+}
+
+func TestMonthlyInvoiceRun(t *testing.T) {
+	router := setupInvoiceRouter()
+	token := loginAdmin(t, router)
+	defer func() {
+		models.TaxRules = make(map[string]*models.TaxRule)
+		models.Invoices = make(map[string]*models.Invoice)
+	}()
+
+	w := doJSON(router, "POST", "/api/admin/companies", token, map[string]interface{}{"legalName": "Billing Partners Ltd"})
+	var company models.Company
+	json.Unmarshal(w.Body.Bytes(), &company)
+	w = doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyId":   company.ID,
+		"joiningDate": "2024-01-01",
+		"department":  "Billing",
+		"projectName": "Ledger",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+
+	// The company rate applies until the vendor's own rate takes over
+	w = doJSON(router, "POST", "/api/admin/companies/"+company.ID+"/rates", token, map[string]interface{}{
+		"unit": "daily", "rate": 100, "currency": "usd", "overtimeMultiplier": 1.5, "effectiveFrom": "2024-01-01",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doJSON(router, "POST", "/api/admin/vendors/"+vendor.ID+"/rates", token, map[string]interface{}{
+		"unit": "daily", "rate": 120, "currency": "USD", "effectiveFrom": "2024-03-16",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doJSON(router, "GET", "/api/admin/vendors/"+vendor.ID+"/rates?date=2024-03-10", token, nil)
+	assert.Contains(t, w.Body.String(), company.ID)
+
+	day := func(date string, hours float64, present float32) *models.Attendance {
+		d, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+		login := d.Add(9 * time.Hour)
+		return &models.Attendance{
+			VendorID:   vendor.ID,
+			Date:       d,
+			LoginTime:  login,
+			LogoutTime: login.Add(time.Duration(hours * float64(time.Hour))),
+			PresentDay: present,
+		}
+	}
+	models.AttendanceRecords[vendor.ID] = []*models.Attendance{
+		day("2024-03-04", 9, 1),
+		day("2024-03-05", 4, 0.5),
+		day("2024-03-18", 8, 1),
+		day("2024-04-01", 8, 1),
+	}
+	defer delete(models.AttendanceRecords, vendor.ID)
+
+	w = doJSON(router, "POST", "/api/admin/tax-rules", token, map[string]interface{}{"name": "VAT", "rate": 20})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var taxRule models.TaxRule
+	json.Unmarshal(w.Body.Bytes(), &taxRule)
+
+	w = doJSON(router, "POST", "/api/admin/invoices/run", token, map[string]string{"month": "2024-03"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var run struct {
+		Invoices []models.Invoice `json:"invoices"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &run)
+	var invoice *models.Invoice
+	for i := range run.Invoices {
+		if run.Invoices[i].VendorID == vendor.ID {
+			invoice = &run.Invoices[i]
+		}
+	}
+	if !assert.NotNil(t, invoice) {
+		return
+	}
+	// 1.5 days at 100, 1 hour overtime at 100/8*1.5, 1 day at 120
+	assert.Len(t, invoice.LineItems, 3)
+	assert.Equal(t, 288.75, invoice.Subtotal)
+	assert.Equal(t, 346.5, invoice.Total)
+	assert.Equal(t, models.InvoiceDraft, invoice.Status)
+
+	w = doJSON(router, "POST", "/api/admin/invoices/"+invoice.ID+"/paid", token, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = doJSON(router, "POST", "/api/admin/invoices/"+invoice.ID+"/approve", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(router, "POST", "/api/admin/invoices/"+invoice.ID+"/paid", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Paid invoices are not regenerated
+	w = doJSON(router, "POST", "/api/admin/invoices/run", token, map[string]string{"month": "2024-03"})
+	assert.Contains(t, w.Body.String(), "Invoice already paid")
+
+	w = doJSON(router, "GET", "/api/admin/invoices/"+invoice.ID+"/pdf", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "%PDF-1.4"))
+	assert.Contains(t, w.Body.String(), invoice.Number)
+
+	w = doJSON(router, "DELETE", "/api/admin/tax-rules/"+taxRule.ID, token, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doJSON(router, "DELETE", "/api/admin/tax-rules/"+taxRule.ID, token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPurchaseOrderConsumption(t *testing.T) {
+	router := setupInvoiceRouter()
+	token := loginAdmin(t, router)
+	api := setupReviewRouter()
+	defer func() {
+		models.Invoices = make(map[string]*models.Invoice)
+		models.PurchaseOrders = make(map[string]*models.PurchaseOrder)
+	}()
+
+	w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "PO Vendor",
+		"joiningDate": "2024-01-01",
+		"department":  "Procurement",
+		"projectName": "Sourcing",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	doJSON(router, "POST", "/api/admin/vendors/"+vendor.ID+"/rates", token, map[string]interface{}{
+		"unit": "daily", "rate": 100, "currency": "EUR", "effectiveFrom": "2024-01-01",
+	})
+	for _, date := range []string{"2024-05-06", "2024-05-07"} {
+		d, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+		models.AttendanceRecords[vendor.ID] = append(models.AttendanceRecords[vendor.ID], &models.Attendance{
+			VendorID: vendor.ID, Date: d, PresentDay: 1,
+		})
+	}
+	defer delete(models.AttendanceRecords, vendor.ID)
+
+	w = doJSON(router, "POST", "/api/admin/purchase-orders", token, map[string]interface{}{
+		"number": "PO-7001", "vendorId": vendor.ID, "amount": 150, "currency": "EUR", "startDate": "2024-01-01",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created struct {
+		PurchaseOrder models.PurchaseOrder `json:"purchaseOrder"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	po := created.PurchaseOrder
+
+	w = doJSON(router, "POST", "/api/admin/invoices/run", token, map[string]string{"month": "2024-05"})
+	var run struct {
+		Invoices []models.Invoice `json:"invoices"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &run)
+	var invoiceID string
+	for _, invoice := range run.Invoices {
+		if invoice.VendorID == vendor.ID {
+			invoiceID = invoice.ID
+		}
+	}
+
+	// The 200 EUR invoice does not fit under the 150 EUR ceiling
+	w = doJSON(router, "POST", "/api/admin/invoices/"+invoiceID+"/approve", token, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = doJSON(router, "PUT", "/api/admin/purchase-orders/"+po.ID, token, map[string]interface{}{
+		"amount": 250, "status": "open",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(router, "POST", "/api/admin/invoices/"+invoiceID+"/approve", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, po.ID, models.Invoices[invoiceID].PurchaseOrderID)
+	assert.Equal(t, 200.0, models.PurchaseOrders[po.ID].Consumed)
+	assert.Equal(t, 75, models.PurchaseOrders[po.ID].AlertedThreshold)
+
+	// Crossing 75% alerts the admins
+	w = doJSON(api, "GET", "/api/notifications", token, nil)
+	assert.Contains(t, w.Body.String(), "PO-7001 is 80% utilized")
+
+	w = doJSON(router, "GET", "/api/admin/spend?groupBy=department", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var summaries []handlers.SpendSummary
+	json.Unmarshal(w.Body.Bytes(), &summaries)
+	found := false
+	for _, summary := range summaries {
+		if totals, exists := summary.Spend["EUR"]; exists {
+			found = true
+			assert.Equal(t, 250.0, totals.Committed)
+			assert.Equal(t, 200.0, totals.Consumed)
+			assert.Equal(t, 200.0, totals.Invoiced)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestRateCardLastDayCoversAttendanceTimes(t *testing.T) {
+	router := setupInvoiceRouter()
+	token := loginAdmin(t, router)
+	defer func() { models.Invoices = make(map[string]*models.Invoice) }()
+
+	w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Late Shift Co",
+		"joiningDate": "2024-01-01",
+		"department":  "Support",
+		"projectName": "Night Desk",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	for _, card := range []map[string]interface{}{
+		{"unit": "daily", "rate": 100, "currency": "USD", "effectiveFrom": "2024-06-01"},
+		{"unit": "daily", "rate": 150, "currency": "USD", "effectiveFrom": "2024-06-17"},
+	} {
+		w = doJSON(router, "POST", "/api/admin/vendors/"+vendor.ID+"/rates", token, card)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+	first := models.RateCards[vendor.ID][0]
+
+	// Attendance carries the time it was recorded, not midnight
+	models.AttendanceRecords[vendor.ID] = []*models.Attendance{
+		{VendorID: vendor.ID, Date: time.Date(2024, 6, 16, 17, 30, 0, 0, time.Local), PresentDay: 1},
+		{VendorID: vendor.ID, Date: time.Date(2024, 6, 17, 8, 15, 0, 0, time.Local), PresentDay: 1},
+	}
+	defer delete(models.AttendanceRecords, vendor.ID)
+
+	w = doJSON(router, "POST", "/api/admin/invoices/run", token, map[string]string{"month": "2024-06"})
+	assert.NotContains(t, w.Body.String(), "No rate card in effect")
+	var run struct {
+		Invoices []models.Invoice `json:"invoices"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &run)
+	var invoice *models.Invoice
+	for i := range run.Invoices {
+		if run.Invoices[i].VendorID == vendor.ID {
+			invoice = &run.Invoices[i]
+		}
+	}
+	if assert.NotNil(t, invoice) {
+		assert.Equal(t, 250.0, invoice.Subtotal)
+	}
+
+	// Days compare in the location the time was recorded in
+	east := time.FixedZone("UTC+10", 10*60*60)
+	assert.True(t, utils.RateCardCovers(first, time.Date(2024, 6, 16, 23, 30, 0, 0, east)))
+	assert.False(t, utils.RateCardCovers(first, time.Date(2024, 6, 17, 0, 30, 0, 0, east)))
+	west := time.FixedZone("UTC-8", -8*60*60)
+	assert.True(t, utils.RateCardCovers(first, time.Date(2024, 6, 1, 0, 30, 0, 0, west)))
+}
+
+func TestPurchaseOrderMatchSkipsExhaustedOrders(t *testing.T) {
+	router := setupInvoiceRouter()
+	token := loginAdmin(t, router)
+	defer func() {
+		models.Invoices = make(map[string]*models.Invoice)
+		models.PurchaseOrders = make(map[string]*models.PurchaseOrder)
+	}()
+
+	w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Two PO Vendor",
+		"joiningDate": "2024-01-01",
+		"department":  "Procurement",
+		"projectName": "Sourcing",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	doJSON(router, "POST", "/api/admin/vendors/"+vendor.ID+"/rates", token, map[string]interface{}{
+		"unit": "daily", "rate": 100, "currency": "EUR", "effectiveFrom": "2024-01-01",
+	})
+	for _, date := range []string{"2024-05-06", "2024-05-07"} {
+		d, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+		models.AttendanceRecords[vendor.ID] = append(models.AttendanceRecords[vendor.ID], &models.Attendance{
+			VendorID: vendor.ID, Date: d, PresentDay: 1,
+		})
+	}
+	defer delete(models.AttendanceRecords, vendor.ID)
+
+	createPO := func(number string, amount float64, startDate string) string {
+		w := doJSON(router, "POST", "/api/admin/purchase-orders", token, map[string]interface{}{
+			"number": number, "vendorId": vendor.ID, "amount": amount, "currency": "EUR", "startDate": startDate,
+		})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var created struct {
+			PurchaseOrder models.PurchaseOrder `json:"purchaseOrder"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &created)
+		return created.PurchaseOrder.ID
+	}
+	earlierID := createPO("PO-7101", 150, "2024-01-01")
+	laterID := createPO("PO-7102", 500, "2024-03-01")
+
+	w = doJSON(router, "POST", "/api/admin/invoices/run", token, map[string]string{"month": "2024-05"})
+	var run struct {
+		Invoices []models.Invoice `json:"invoices"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &run)
+	var invoiceID string
+	for _, invoice := range run.Invoices {
+		if invoice.VendorID == vendor.ID {
+			invoiceID = invoice.ID
+		}
+	}
+
+	// The 200 EUR invoice does not fit the earlier order, so the later one
+	// that can take it is consumed instead
+	w = doJSON(router, "POST", "/api/admin/invoices/"+invoiceID+"/approve", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, laterID, models.Invoices[invoiceID].PurchaseOrderID)
+	assert.Equal(t, 0.0, models.PurchaseOrders[earlierID].Consumed)
+	assert.Equal(t, 200.0, models.PurchaseOrders[laterID].Consumed)
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMyDocumentsRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	api.GET("/my-documents", handlers.GetMyDocuments)
+	api.GET("/my-documents/:id/download", handlers.DownloadMyDocument)
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.POST("/documents", handlers.UploadDocument)
+		admin.PUT("/documents/:id/visibility", handlers.SetDocumentVisibility)
+	}
+	return r
+}
+
+func TestVendorDownloadsOwnDocuments(t *testing.T) {
+	useStubScanner(t)
+	router := setupMyDocumentsRouter()
+	adminToken := loginAdmin(t, router)
+
+	user := &models.User{ID: "download-vendor-user", Name: "Download Vendor", Email: "download@vendor.com", Role: models.VendorRole}
+	models.Users[user.ID] = user
+	defer delete(models.Users, user.ID)
+	vendorToken, _ := middleware.GenerateToken(user.ID, string(user.Role))
+
+	createVendor := func(name string) string {
+		w := doJSON(router, "POST", "/api/admin/vendors", adminToken, map[string]string{
+			"companyName": name,
+			"joiningDate": "2024-01-01",
+			"department":  "Legal",
+			"projectName": "Contracts",
+		})
+		var vendor models.Vendor
+		json.Unmarshal(w.Body.Bytes(), &vendor)
+		return vendor.ID
+	}
+	vendorID, otherVendorID := createVendor("Downloads Co"), createVendor("Someone Else Co")
+	models.Vendors[vendorID].UserID = user.ID
+
+	upload := func(vendorID, name, content, adminOnly string) string {
+		w := uploadDocument(t, router, "/api/admin/documents", adminToken, map[string]string{
+			"vendorId": vendorID, "type": "agreement", "adminOnly": adminOnly,
+		}, name, []byte(content))
+		var doc models.Document
+		json.Unmarshal(w.Body.Bytes(), &doc)
+		return doc.ID
+	}
+	ownID := upload(vendorID, "contract.pdf", "%PDF-1.4 vendor contract", "false")
+	hiddenID := upload(vendorID, "background-check.pdf", "%PDF-1.4 background check", "true")
+	otherID := upload(otherVendorID, "other.pdf", "%PDF-1.4 other contract", "false")
+
+	w := doJSON(router, "GET", "/api/my-documents", vendorToken, nil)
+	var mine []models.Document
+	json.Unmarshal(w.Body.Bytes(), &mine)
+	if assert.Len(t, mine, 1) {
+		assert.Equal(t, ownID, mine[0].ID)
+	}
+
+	w = doJSON(router, "GET", "/api/my-documents/"+ownID+"/download", vendorToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "%PDF-1.4 vendor contract", w.Body.String())
+	assert.Equal(t, `attachment; filename=contract.pdf`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/my-documents/"+ownID+"/download", nil)
+	req.Header.Set("Authorization", "Bearer "+vendorToken)
+	req.Header.Set("Range", "bytes=9-14")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "vendor", w.Body.String())
+
+	// Other vendors' and admin-only documents look the same as missing ones
+	for _, id := range []string{hiddenID, otherID, "missing"} {
+		w = doJSON(router, "GET", "/api/my-documents/"+id+"/download", vendorToken, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	}
+	w = doJSON(router, "GET", "/api/my-documents/"+ownID+"/download", adminToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doJSON(router, "PUT", "/api/admin/documents/"+hiddenID+"/visibility", adminToken, map[string]bool{"adminOnly": false})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(router, "GET", "/api/my-documents/"+hiddenID+"/download", vendorToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(router, "GET", "/api/my-documents", vendorToken, nil)
+	json.Unmarshal(w.Body.Bytes(), &mine)
+	assert.Len(t, mine, 2)
+
+	// A trashed vendor record of the same user is passed over, and once the
+	// current one is trashed too there are no documents to list
+	formerID := createVendor("Former Downloads Co")
+	upload(formerID, "former.pdf", "%PDF-1.4 former contract", "false")
+	deletedAt := time.Now()
+	models.Vendors[formerID].UserID = user.ID
+	models.Vendors[formerID].DeletedAt = &deletedAt
+	w = doJSON(router, "GET", "/api/my-documents", vendorToken, nil)
+	json.Unmarshal(w.Body.Bytes(), &mine)
+	assert.Len(t, mine, 2)
+	models.Vendors[vendorID].DeletedAt = &deletedAt
+	w = doJSON(router, "GET", "/api/my-documents", vendorToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+}
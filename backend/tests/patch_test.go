@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupPatchRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.PATCH("/vendors/:id", handlers.PatchVendor)
+		admin.POST("/assets", handlers.CreateAsset)
+		admin.PATCH("/assets/:id", handlers.PatchAsset)
+	}
+	return r
+}
+
+func doPatch(router *gin.Engine, path, token, ifMatch, contentType, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPatchVendorWithIfMatch(t *testing.T) {
+	router := setupPatchRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Patch Co",
+		"joiningDate": "2024-02-01",
+		"endDate":     "2024-12-31",
+		"department":  "Finance",
+		"projectName": "Ledger",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+	var created map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	path := "/api/admin/vendors/" + created["id"].(string)
+
+	// Only the patched fields change; null removes the optional end date
+	w = doPatch(router, path, token, etag, "application/merge-patch+json", `{"projectName":"Audit","endDate":null}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	var patched map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &patched)
+	assert.Equal(t, "Audit", patched["projectName"])
+	assert.Equal(t, "Patch Co", patched["companyName"])
+	assert.Equal(t, "0001-01-01T00:00:00Z", patched["endDate"])
+
+	// A write based on the stale version is rejected
+	w = doPatch(router, path, token, etag, "application/merge-patch+json", `{"department":"HR"}`)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	// Read-only, invalid and wrongly typed patches are rejected
+	w = doPatch(router, path, token, "", "application/merge-patch+json", `{"status":"inactive"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doPatch(router, path, token, "", "application/merge-patch+json", `{"companyName":null}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doPatch(router, path, token, "", "text/plain", `{"department":"HR"}`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestPatchAsset(t *testing.T) {
+	router := setupPatchRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/assets", token, map[string]string{
+		"name":         "ThinkPad",
+		"type":         "laptop",
+		"serialNumber": "SN-1",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	path := "/api/admin/assets/" + created["id"].(string)
+
+	w = doPatch(router, path, token, `"1"`, "application/merge-patch+json", `{"serialNumber":"SN-2"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var patched map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &patched)
+	assert.Equal(t, "SN-2", patched["serialNumber"])
+	assert.Equal(t, "ThinkPad", patched["name"])
+	assert.EqualValues(t, 2, patched["version"])
+}
+
+func TestConcurrentPatchesWithSameETag(t *testing.T) {
+	router := setupPatchRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/assets", token, map[string]string{
+		"name":         "Monitor",
+		"type":         "monitor",
+		"serialNumber": "MN-0",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	path := "/api/admin/assets/" + created["id"].(string)
+
+	// Of the writes based on the same version only one may succeed
+	const writers = 20
+	codes := make(chan int, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"serialNumber":"MN-%d"}`, i+1)
+			codes <- doPatch(router, path, token, `"1"`, "application/merge-patch+json", body).Code
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+
+	counts := make(map[int]int)
+	for code := range codes {
+		counts[code]++
+	}
+	assert.Equal(t, map[int]int{http.StatusOK: 1, http.StatusPreconditionFailed: writers - 1}, counts)
+}
@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+	"vendor-management/preview"
+	"vendor-management/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupPreviewRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.POST("/documents", handlers.UploadDocument)
+		admin.GET("/documents/:id/preview", handlers.GetDocumentPreview)
+	}
+	return r
+}
+
+// scanImage draws a test card: a dark band across the top of a light page
+func scanImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{240, 240, 240, 255}
+			if y < height/4 {
+				c = color.RGBA{20, 40, 160, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// scannedPDF builds a PDF whose single page is a JPEG image, the way
+// scanners produce them
+func scannedPDF(img image.Image, flate bool) []byte {
+	bounds := img.Bounds()
+	var stream bytes.Buffer
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8",
+		bounds.Dx(), bounds.Dy())
+	if flate {
+		w := zlib.NewWriter(&stream)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				r, g, b, _ := img.At(x, y).RGBA()
+				w.Write([]byte{byte(r >> 8), byte(g >> 8), byte(b >> 8)})
+			}
+		}
+		w.Close()
+		dict += " /Filter /FlateDecode"
+	} else {
+		jpeg.Encode(&stream, img, nil)
+		dict += " /Filter /DCTDecode"
+	}
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	pdf.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
+	pdf.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Im1 4 0 R >> >> >>\nendobj\n")
+	fmt.Fprintf(&pdf, "4 0 obj\n<< %s /Length %d >>\nstream\n", dict, stream.Len())
+	pdf.Write(stream.Bytes())
+	pdf.WriteString("\nendstream\nendobj\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+// awaitPreview polls the preview endpoint until generation has finished
+func awaitPreview(t *testing.T, router *gin.Engine, token, path string) *httptest.ResponseRecorder {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w := doJSON(router, "GET", path, token, nil)
+		if w.Code != http.StatusAccepted || time.Now().After(deadline) {
+			return w
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestDocumentPreviews(t *testing.T) {
+	useStubScanner(t)
+	router := setupPreviewRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Preview Co",
+		"joiningDate": "2024-01-01",
+		"department":  "Security",
+		"projectName": "Badges",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	upload := func(docType, name string, content []byte) *models.Document {
+		w := uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+			"vendorId": vendor.ID, "type": docType,
+		}, name, content)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var doc models.Document
+		json.Unmarshal(w.Body.Bytes(), &doc)
+		return models.Documents[doc.ID]
+	}
+
+	var photo bytes.Buffer
+	png.Encode(&photo, scanImage(1600, 1000))
+	photoDoc := upload("id_proof", "passport.png", photo.Bytes())
+
+	w = awaitPreview(t, router, token, "/api/admin/documents/"+photoDoc.ID+"/preview")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/jpeg", w.Header().Get("Content-Type"))
+	img, err := jpeg.Decode(w.Body)
+	if assert.NoError(t, err) {
+		assert.Equal(t, image.Rect(0, 0, 1024, 640), img.Bounds())
+		r, g, b, _ := img.At(512, 50).RGBA()
+		assert.InDelta(t, 20, r>>8, 12)
+		assert.InDelta(t, 40, g>>8, 12)
+		assert.InDelta(t, 160, b>>8, 12)
+	}
+	w = awaitPreview(t, router, token, "/api/admin/documents/"+photoDoc.ID+"/preview?size=thumbnail")
+	img, err = jpeg.Decode(w.Body)
+	if assert.NoError(t, err) {
+		assert.Equal(t, image.Rect(0, 0, 200, 125), img.Bounds())
+	}
+
+	// Previews are cached next to the original in the storage backend
+	_, err = storage.Store.Open(preview.Key(photoDoc.StorageKey, preview.Thumbnail))
+	assert.NoError(t, err)
+
+	// Scanned PDFs preview as their page image
+	for _, flate := range []bool{false, true} {
+		scan := upload("id_proof", fmt.Sprintf("scan-%t.pdf", flate), scannedPDF(scanImage(800, 1200), flate))
+		w = awaitPreview(t, router, token, "/api/admin/documents/"+scan.ID+"/preview?size=thumbnail")
+		assert.Equal(t, http.StatusOK, w.Code)
+		img, err = jpeg.Decode(w.Body)
+		if assert.NoError(t, err) {
+			assert.Equal(t, image.Rect(0, 0, 133, 200), img.Bounds())
+		}
+	}
+
+	// Text-only PDFs have nothing to show without a renderer, and other
+	// formats are not previewed at all
+	text := upload("agreement", "terms.pdf", pdfContent(false, "Terms of engagement"))
+	w = awaitPreview(t, router, token, "/api/admin/documents/"+text.ID+"/preview")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	word := upload("agreement", "terms.docx", docxContent("Terms of engagement"))
+	w = doJSON(router, "GET", "/api/admin/documents/"+word.ID+"/preview", token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doJSON(router, "GET", "/api/admin/documents/"+photoDoc.ID+"/preview?size=huge", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFitFlattensTransparency(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img.Set(0, 0, color.NRGBA{0, 0, 0, 255})
+	fitted := preview.Fit(img, 20)
+	assert.Equal(t, image.Rect(0, 0, 10, 10), fitted.Bounds())
+	assert.Equal(t, color.RGBA{0, 0, 0, 255}, fitted.At(0, 0))
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, fitted.At(5, 5))
+}
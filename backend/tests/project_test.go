@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupProjectRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/departments", handlers.CreateDepartment)
+		admin.POST("/projects", handlers.CreateProject)
+		admin.GET("/projects/:id/roster", handlers.GetProjectRoster)
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.PATCH("/vendors/:id", handlers.PatchVendor)
+		admin.GET("/vendors/:id/assignments", handlers.GetVendorAssignments)
+	}
+	return r
+}
+
+func TestManagedDepartmentsAndProjects(t *testing.T) {
+	router := setupProjectRouter()
+	token := loginAdmin(t, router)
+
+	// Managed entities make free-text names strict, so clean up afterwards
+	defer func() {
+		models.Departments = make(map[string]*models.Department)
+		models.Projects = make(map[string]*models.Project)
+	}()
+
+	w := doJSON(router, "POST", "/api/admin/departments", token, map[string]interface{}{
+		"code": "ENG", "name": "Engineering", "budget": 100000,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var dept models.Department
+	json.Unmarshal(w.Body.Bytes(), &dept)
+
+	w = doJSON(router, "POST", "/api/admin/departments", token, map[string]interface{}{
+		"code": "eng", "name": "Eng",
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var projects [2]models.Project
+	for i, code := range []string{"APOLLO", "GEMINI"} {
+		w = doJSON(router, "POST", "/api/admin/projects", token, map[string]interface{}{
+			"code": code, "name": code, "departmentId": dept.ID, "startDate": "2024-01-01",
+		})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		json.Unmarshal(w.Body.Bytes(), &projects[i])
+	}
+
+	// Names are resolved case-insensitively to managed entities
+	w = doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Roster Co",
+		"joiningDate": "2024-01-10",
+		"department":  "eng",
+		"projectName": "apollo",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	assert.Equal(t, dept.ID, vendor.DepartmentID)
+	assert.Equal(t, "Engineering", vendor.Department)
+	assert.Equal(t, projects[0].ID, vendor.ProjectID)
+
+	w = doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Roster Co",
+		"joiningDate": "2024-01-10",
+		"department":  "Engg",
+		"projectName": "APOLLO",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Reassignment closes the previous assignment and updates the rosters
+	w = doPatch(router, "/api/admin/vendors/"+vendor.ID, token, "", "application/merge-patch+json",
+		`{"projectId":"`+projects[1].ID+`"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doJSON(router, "GET", "/api/admin/vendors/"+vendor.ID+"/assignments", token, nil)
+	var assignments []models.ProjectAssignment
+	json.Unmarshal(w.Body.Bytes(), &assignments)
+	if assert.Len(t, assignments, 2) {
+		assert.NotNil(t, assignments[0].EndedAt)
+		assert.Equal(t, projects[1].ID, assignments[1].ProjectID)
+	}
+
+	w = doJSON(router, "GET", "/api/admin/projects/"+projects[0].ID+"/roster", token, nil)
+	assert.NotContains(t, w.Body.String(), vendor.ID)
+	w = doJSON(router, "GET", "/api/admin/projects/"+projects[1].ID+"/roster", token, nil)
+	assert.Contains(t, w.Body.String(), vendor.ID)
+
+	// Patching only a name moves the vendor even though the patch base still
+	// carries the old IDs
+	w = doJSON(router, "POST", "/api/admin/departments", token, map[string]interface{}{"code": "SAL", "name": "Sales"})
+	var sales models.Department
+	json.Unmarshal(w.Body.Bytes(), &sales)
+	w = doJSON(router, "POST", "/api/admin/projects", token, map[string]interface{}{
+		"code": "SHARED", "name": "Shared Services", "startDate": "2024-01-01",
+	})
+	var shared models.Project
+	json.Unmarshal(w.Body.Bytes(), &shared)
+	w = doPatch(router, "/api/admin/vendors/"+vendor.ID, token, "", "application/merge-patch+json",
+		`{"department":"Sales","projectName":"shared"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	assert.Equal(t, sales.ID, vendor.DepartmentID)
+	assert.Equal(t, shared.ID, vendor.ProjectID)
+
+	// A name that disagrees with the ID is refused rather than ignored
+	w = doPatch(router, "/api/admin/vendors/"+vendor.ID, token, "", "application/merge-patch+json",
+		`{"departmentId":"`+dept.ID+`","department":"Marketing"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "does not match")
+
+	// Vendors with free-text names from before departments were managed can
+	// still be updated, but not moved to another unknown name
+	legacy := &models.Vendor{
+		ID: "legacy-vendor", CompanyName: "Legacy Co", JoiningDate: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		Department: "Old Ops", ProjectName: "Old Project",
+		Status: "active", Version: 1, Documents: []models.Document{}, Assets: []models.Asset{},
+	}
+	models.Vendors[legacy.ID] = legacy
+	defer delete(models.Vendors, legacy.ID)
+	w = doPatch(router, "/api/admin/vendors/"+legacy.ID, token, "", "application/merge-patch+json", `{"type":"consultant"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Old Ops", legacy.Department)
+	assert.Equal(t, "consultant", legacy.Type)
+	w = doPatch(router, "/api/admin/vendors/"+legacy.ID, token, "", "application/merge-patch+json", `{"department":"Older Ops"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doPatch(router, "/api/admin/vendors/"+legacy.ID, token, "", "application/merge-patch+json", `{"department":"sales"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, sales.ID, legacy.DepartmentID)
+}
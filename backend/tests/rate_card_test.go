@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRateCardRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/companies", handlers.CreateCompany)
+		admin.GET("/companies/:id/rates", handlers.ListCompanyRates)
+		admin.POST("/companies/:id/rates", handlers.CreateCompanyRate)
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.GET("/vendors/:id/rates", handlers.ListVendorRates)
+		admin.POST("/vendors/:id/rates", handlers.CreateVendorRate)
+	}
+	return r
+}
+
+func TestRateCards(t *testing.T) {
+	router := setupRateCardRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/companies", token, map[string]interface{}{"legalName": "Rates Ltd"})
+	var company models.Company
+	json.Unmarshal(w.Body.Bytes(), &company)
+	w = doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyId":   company.ID,
+		"joiningDate": "2024-01-01",
+		"department":  "Finance",
+		"projectName": "Rates",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	defer delete(models.RateCards, vendor.ID)
+	defer delete(models.RateCards, company.ID)
+	vendorRates := "/api/admin/vendors/" + vendor.ID + "/rates"
+
+	w = doJSON(router, "POST", vendorRates, token, map[string]interface{}{
+		"unit": "daily", "rate": 100, "currency": "dollars", "effectiveFrom": "2024-01-01",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(router, "POST", vendorRates, token, map[string]interface{}{
+		"unit": "weekly", "rate": 100, "currency": "USD", "effectiveFrom": "2024-01-01",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// A new card closes the open-ended one the day before it starts
+	w = doJSON(router, "POST", vendorRates, token, map[string]interface{}{
+		"unit": "daily", "rate": 100, "currency": "usd", "effectiveFrom": "2024-01-01",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var first models.RateCard
+	json.Unmarshal(w.Body.Bytes(), &first)
+	assert.Equal(t, "USD", first.Currency)
+	assert.Equal(t, 1.0, first.OvertimeMultiplier)
+	assert.Equal(t, 1, first.Version)
+
+	w = doJSON(router, "POST", vendorRates, token, map[string]interface{}{
+		"unit": "hourly", "rate": 15, "currency": "USD", "effectiveFrom": "2024-04-01", "effectiveTo": "2024-06-30",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = doJSON(router, "GET", vendorRates, token, nil)
+	var cards []models.RateCard
+	json.Unmarshal(w.Body.Bytes(), &cards)
+	if assert.Len(t, cards, 2) {
+		assert.Equal(t, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), cards[0].EffectiveTo)
+		assert.Equal(t, 2, cards[1].Version)
+	}
+
+	// Cards must follow the current one without overlapping it
+	for _, from := range []string{"2024-03-01", "2024-04-01", "2024-06-30"} {
+		w = doJSON(router, "POST", vendorRates, token, map[string]interface{}{
+			"unit": "daily", "rate": 130, "currency": "USD", "effectiveFrom": from,
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code, from)
+	}
+	w = doJSON(router, "POST", vendorRates, token, map[string]interface{}{
+		"unit": "daily", "rate": 120, "currency": "USD", "effectiveFrom": "2024-06-01", "effectiveTo": "2024-05-01",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Lookups by date fall back to the company's card in gaps
+	w = doJSON(router, "POST", "/api/admin/companies/"+company.ID+"/rates", token, map[string]interface{}{
+		"unit": "monthly", "rate": 4000, "currency": "USD", "effectiveFrom": "2023-01-01",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var companyCard models.RateCard
+	json.Unmarshal(w.Body.Bytes(), &companyCard)
+
+	lookup := func(date string) (int, string) {
+		w := doJSON(router, "GET", vendorRates+"?date="+date, token, nil)
+		var card models.RateCard
+		json.Unmarshal(w.Body.Bytes(), &card)
+		return w.Code, card.ID
+	}
+	code, id := lookup("2024-03-31")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, first.ID, id)
+	_, id = lookup("2024-04-01")
+	assert.Equal(t, cards[1].ID, id)
+	_, id = lookup("2024-07-01")
+	assert.Equal(t, companyCard.ID, id)
+	code, _ = lookup("2024-13-01")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	w = doJSON(router, "GET", "/api/admin/companies/"+company.ID+"/rates?date=2022-12-31", token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+	"vendor-management/storage"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRetentionRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.DELETE("/vendors/:id", handlers.DeleteVendor)
+		admin.PUT("/vendors/:id/legal-hold", handlers.PlaceVendorLegalHold)
+		admin.DELETE("/vendors/:id/legal-hold", handlers.ReleaseVendorLegalHold)
+		admin.POST("/documents", handlers.UploadDocument)
+		admin.DELETE("/documents/:id", handlers.DeleteDocument)
+		admin.PUT("/documents/:id/legal-hold", handlers.PlaceDocumentLegalHold)
+		admin.DELETE("/documents/:id/legal-hold", handlers.ReleaseDocumentLegalHold)
+		admin.GET("/retention/rules", handlers.ListRetentionRules)
+		admin.POST("/retention/rules", handlers.CreateRetentionRule)
+		admin.DELETE("/retention/rules/:id", handlers.DeleteRetentionRule)
+		admin.POST("/retention/run", handlers.RunDisposal)
+		admin.GET("/retention/certificates", handlers.ListDisposalCertificates)
+		admin.GET("/retention/certificates/:id", handlers.GetDisposalCertificate)
+	}
+	return r
+}
+
+func TestRetentionAndLegalHold(t *testing.T) {
+	router := setupRetentionRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/retention/rules", token, map[string]interface{}{
+		"documentType": "agreement", "years": 7,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var rule models.RetentionRule
+	json.Unmarshal(w.Body.Bytes(), &rule)
+	defer delete(models.RetentionRules, rule.ID)
+
+	w = doJSON(router, "POST", "/api/admin/retention/rules", token, map[string]interface{}{
+		"documentType": "agreement", "years": 10,
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = doJSON(router, "POST", "/api/admin/retention/rules", token, map[string]interface{}{
+		"documentType": "nda",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Former Co",
+		"joiningDate": time.Now().AddDate(-10, 0, 0).Format("2006-01-02"),
+		"endDate":     time.Now().AddDate(-8, 0, 0).Format("2006-01-02"),
+		"department":  "Legal",
+		"projectName": "Archive",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+
+	upload := func(docType, name, content string) *models.Document {
+		w := uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+			"vendorId": vendor.ID, "type": docType,
+		}, name, []byte(content))
+		var doc models.Document
+		json.Unmarshal(w.Body.Bytes(), &doc)
+		return models.Documents[doc.ID]
+	}
+	expired := upload("agreement", "old.pdf", "%PDF-1.4 old agreement")
+	held := upload("agreement", "disputed.pdf", "%PDF-1.4 disputed agreement")
+	unruled := upload("nda", "nda.pdf", "%PDF-1.4 non-disclosure")
+
+	// Held documents cannot be deleted
+	w = doJSON(router, "PUT", "/api/admin/documents/"+held.ID+"/legal-hold", token, map[string]string{"reason": "Pending litigation"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(router, "DELETE", "/api/admin/documents/"+held.ID, token, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Only the expired document without a hold is disposed of
+	w = doJSON(router, "POST", "/api/admin/retention/run", token, nil)
+	var certificates []models.DisposalCertificate
+	json.Unmarshal(w.Body.Bytes(), &certificates)
+	if assert.Len(t, certificates, 1) {
+		certificate := certificates[0]
+		assert.Equal(t, expired.ID, certificate.DocumentID)
+		assert.Equal(t, rule.ID, certificate.RuleID)
+		assert.Equal(t, "Former Co", certificate.VendorName)
+		assert.Equal(t, expired.Checksum, certificate.Files[0].Checksum)
+		assert.Equal(t, vendor.EndDate.AddDate(7, 0, 0), certificate.RetainUntil)
+		defer delete(models.DisposalCertificates, certificate.ID)
+
+		w = doJSON(router, "GET", "/api/admin/retention/certificates/"+certificate.ID, token, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	assert.NotContains(t, models.Documents, expired.ID)
+	_, err := storage.Store.Open(expired.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.Contains(t, models.Documents, held.ID)
+	assert.Contains(t, models.Documents, unruled.ID)
+
+	// A vendor hold covers all of its documents and the vendor itself
+	doJSON(router, "DELETE", "/api/admin/documents/"+held.ID+"/legal-hold", token, nil)
+	w = doJSON(router, "PUT", "/api/admin/vendors/"+vendor.ID+"/legal-hold", token, map[string]string{"reason": "Audit"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(router, "DELETE", "/api/admin/vendors/"+vendor.ID, token, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = doJSON(router, "DELETE", "/api/admin/documents/"+unruled.ID, token, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Empty(t, utils.DisposeExpiredDocuments())
+
+	w = doJSON(router, "DELETE", "/api/admin/vendors/"+vendor.ID+"/legal-hold", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	disposed := utils.DisposeExpiredDocuments()
+	if assert.Len(t, disposed, 1) {
+		assert.Equal(t, held.ID, disposed[0].DocumentID)
+		defer delete(models.DisposalCertificates, disposed[0].ID)
+	}
+	for _, doc := range models.Vendors[vendor.ID].Documents {
+		assert.NotEqual(t, held.ID, doc.ID)
+	}
+
+	// Documents held in the trash are not purged
+	w = doJSON(router, "DELETE", "/api/admin/documents/"+unruled.ID, token, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doJSON(router, "PUT", "/api/admin/documents/"+unruled.ID+"/legal-hold", token, map[string]string{"reason": "Audit"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	longAgo := time.Now().AddDate(-1, 0, 0)
+	unruled.DeletedAt = &longAgo
+	utils.PurgeTrash()
+	assert.Contains(t, models.Documents, unruled.ID)
+
+	doJSON(router, "DELETE", "/api/admin/documents/"+unruled.ID+"/legal-hold", token, nil)
+	utils.PurgeTrash()
+	assert.NotContains(t, models.Documents, unruled.ID)
+
+	w = doJSON(router, "DELETE", "/api/admin/retention/rules/"+rule.ID, token, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doJSON(router, "DELETE", "/api/admin/retention/rules/"+rule.ID, token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupReviewRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	api.GET("/notifications", handlers.GetMyNotifications)
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.GET("/vendors/:id", handlers.GetVendor)
+		admin.POST("/vendors/:id/reviews", handlers.CreateReview)
+		admin.GET("/reviews/leaderboard", handlers.GetReviewLeaderboard)
+	}
+	return r
+}
+
+func TestPerformanceReviews(t *testing.T) {
+	router := setupReviewRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Review Co",
+		"joiningDate": "2020-01-01",
+		"department":  "Review-Dept",
+		"projectName": "Scorecard",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+
+	// A vendor never reviewed since joining long ago is due a review
+	utils.SendReviewReminders()
+	w = doJSON(router, "GET", "/api/notifications", token, nil)
+	assert.Contains(t, w.Body.String(), vendor.ID)
+
+	review := map[string]interface{}{
+		"periodStart": "2024-01-01",
+		"periodEnd":   "2024-03-31",
+		"scores":      map[string]float64{"quality": 5, "timeliness": 4, "communication": 3},
+		"comments":    "Solid quarter",
+	}
+	w = doJSON(router, "POST", "/api/admin/vendors/"+vendor.ID+"/reviews", token, review)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.PerformanceReview
+	json.Unmarshal(w.Body.Bytes(), &created)
+	assert.Equal(t, 4.0, created.OverallScore)
+
+	review["scores"] = map[string]float64{"quality": 6, "timeliness": 4, "communication": 3}
+	w = doJSON(router, "POST", "/api/admin/vendors/"+vendor.ID+"/reviews", token, review)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	review["scores"] = map[string]float64{"quality": 5}
+	w = doJSON(router, "POST", "/api/admin/vendors/"+vendor.ID+"/reviews", token, review)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The aggregated rating is returned with the vendor
+	w = doJSON(router, "GET", "/api/admin/vendors/"+vendor.ID, token, nil)
+	var resp struct {
+		Rating handlers.VendorRating `json:"rating"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, 1, resp.Rating.Count)
+	assert.Equal(t, 4.0, resp.Rating.Average)
+	assert.Equal(t, 5.0, resp.Rating.ByCriterion["quality"])
+
+	w = doJSON(router, "GET", "/api/admin/reviews/leaderboard?department=Review-Dept", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var board []struct {
+		Department string `json:"department"`
+		Vendors    []struct {
+			VendorID string `json:"vendorId"`
+		} `json:"vendors"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &board)
+	if assert.Len(t, board, 1) {
+		assert.Equal(t, vendor.ID, board[0].Vendors[0].VendorID)
+	}
+}
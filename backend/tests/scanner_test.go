@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+	"vendor-management/scanner"
+	"vendor-management/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const eicarTestFile = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// useStubScanner opts the test in to the stub scanner, as the server would
+// with SCANNER_BACKEND=stub, so that uploads are found clean
+func useStubScanner(t *testing.T) {
+	previous := scanner.Default
+	scanner.Default = scanner.StubScanner{}
+	t.Cleanup(func() { scanner.Default = previous })
+}
+
+// fixedScanner returns the same verdict for every file
+type fixedScanner struct {
+	result *scanner.Result
+	err    error
+}
+
+func (s fixedScanner) Scan(r io.Reader) (*scanner.Result, error) {
+	io.Copy(io.Discard, r)
+	return s.result, s.err
+}
+
+func setupScannerRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.POST("/documents", handlers.UploadDocument)
+		admin.GET("/documents/:id", handlers.GetDocument)
+		admin.GET("/quarantine", handlers.ListQuarantine)
+		admin.POST("/quarantine/rescan", handlers.RunRescan)
+		admin.DELETE("/quarantine/:id", handlers.DeleteQuarantinedFile)
+	}
+	return r
+}
+
+func TestMalwareScanning(t *testing.T) {
+	router := setupScannerRouter()
+	token := loginAdmin(t, router)
+	useStubScanner(t)
+	defer func() {
+		for _, file := range models.QuarantinedFiles {
+			storage.Store.Delete(file.StorageKey)
+		}
+		models.QuarantinedFiles = make(map[string]*models.QuarantinedFile)
+	}()
+
+	w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Scanned Co",
+		"joiningDate": "2024-01-01",
+		"department":  "Legal",
+		"projectName": "Contracts",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	upload := func(name, content string) *models.Document {
+		w := uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+			"vendorId": vendor.ID, "type": "agreement",
+		}, name, []byte(content))
+		if w.Code != http.StatusCreated {
+			return nil
+		}
+		var doc models.Document
+		json.Unmarshal(w.Body.Bytes(), &doc)
+		return models.Documents[doc.ID]
+	}
+
+	doc := upload("clean.pdf", "%PDF-1.4 clean terms")
+	assert.Equal(t, models.ScanClean, doc.ScanStatus)
+
+	// Infected uploads are refused and kept in quarantine only
+	assert.Nil(t, upload("infected.pdf", "%PDF-1.4 "+eicarTestFile))
+	w = doJSON(router, "GET", "/api/admin/quarantine", token, nil)
+	var quarantined []models.QuarantinedFile
+	json.Unmarshal(w.Body.Bytes(), &quarantined)
+	if assert.Len(t, quarantined, 1) {
+		assert.Equal(t, "Eicar-Test-Signature", quarantined[0].Signature)
+		assert.Equal(t, vendor.ID, quarantined[0].OwnerID)
+		assert.True(t, strings.HasPrefix(quarantined[0].StorageKey, "quarantine/"))
+	}
+
+	// Without a configured scanner uploads are held back rather than trusted
+	scanner.Default, _ = scanner.New("")
+	unscanned := upload("unscanned.pdf", "%PDF-1.4 unscanned terms")
+	assert.Equal(t, models.ScanPending, unscanned.ScanStatus)
+	doJSON(router, "POST", "/api/admin/quarantine/rescan", token, nil)
+	assert.Equal(t, models.ScanPending, unscanned.ScanStatus)
+
+	// Files stored while the scanner is down are held back until rescanned
+	scanner.Default = fixedScanner{err: errors.New("clamd unavailable")}
+	pending := upload("pending.pdf", "%PDF-1.4 pending terms")
+	assert.Equal(t, models.ScanPending, pending.ScanStatus)
+	w = doJSON(router, "GET", "/api/admin/documents/"+pending.ID, token, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	scanner.Default = scanner.StubScanner{}
+	doJSON(router, "POST", "/api/admin/quarantine/rescan", token, nil)
+	assert.Equal(t, models.ScanClean, pending.ScanStatus)
+	w = doJSON(router, "GET", "/api/admin/documents/"+pending.ID, token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// A rescan that finds malware moves the file to quarantine
+	scanner.Default = fixedScanner{err: errors.New("clamd unavailable")}
+	late := upload("late.pdf", "%PDF-1.4 late terms")
+	scanner.Default = fixedScanner{result: &scanner.Result{Infected: true, Signature: "Test.Late"}}
+	originalKey := late.StorageKey
+	doJSON(router, "POST", "/api/admin/quarantine/rescan", token, nil)
+	assert.Equal(t, models.ScanInfected, late.ScanStatus)
+	assert.True(t, strings.HasPrefix(late.StorageKey, "quarantine/"))
+	_, err := storage.Store.Open(originalKey)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	w = doJSON(router, "GET", "/api/admin/documents/"+late.ID, token, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var lateFile *models.QuarantinedFile
+	for _, file := range models.QuarantinedFiles {
+		if file.DocumentID == late.ID {
+			lateFile = file
+		}
+	}
+	if assert.NotNil(t, lateFile) {
+		w = doJSON(router, "DELETE", "/api/admin/quarantine/"+lateFile.ID, token, nil)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Len(t, models.QuarantinedFiles, 1)
+	}
+}
+
+// fakeClamd answers one INSTREAM request the way clamd does
+func fakeClamd(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			r := bufio.NewReader(conn)
+			command, _ := r.ReadString(0)
+			var stream bytes.Buffer
+			for command == "zINSTREAM\x00" {
+				var size uint32
+				if binary.Read(r, binary.BigEndian, &size) != nil || size == 0 {
+					break
+				}
+				io.CopyN(&stream, r, int64(size))
+			}
+			if bytes.Contains(stream.Bytes(), []byte(eicarTestFile)) {
+				conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+			} else {
+				conn.Write([]byte("stream: OK\x00"))
+			}
+			conn.Close()
+		}
+	}()
+	return "tcp://" + listener.Addr().String()
+}
+
+// TestClamdScanner runs against a stand-in for clamd, or against a real
+// daemon when CLAMD_TEST_ADDRESS is set:
+//
+//	docker run -p 3310:3310 clamav/clamav
+//	CLAMD_TEST_ADDRESS=tcp://localhost:3310 go test ./tests -run TestClamdScanner
+func TestClamdScanner(t *testing.T) {
+	address := os.Getenv("CLAMD_TEST_ADDRESS")
+	if address == "" {
+		address = fakeClamd(t)
+	}
+	clamd, err := scanner.NewClamdScanner(address, 10*time.Second)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	result, err := clamd.Scan(strings.NewReader("%PDF-1.4 nothing to see"))
+	if assert.NoError(t, err) {
+		assert.False(t, result.Infected)
+	}
+	result, err = clamd.Scan(strings.NewReader(eicarTestFile))
+	if assert.NoError(t, err) {
+		assert.True(t, result.Infected)
+		assert.Equal(t, "Eicar-Test-Signature", result.Signature)
+	}
+
+	_, err = scanner.NewClamdScanner("localhost:3310", time.Second)
+	assert.Error(t, err)
+}
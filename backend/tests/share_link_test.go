@@ -0,0 +1,220 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+	"vendor-management/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupShareLinkRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+	r.GET("/api/shared/documents/:id", handlers.DownloadSharedDocument)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.POST("/documents", handlers.UploadDocument)
+		admin.POST("/documents/:id/share", handlers.CreateShareLink)
+		admin.GET("/documents/:id/shares", handlers.ListShareLinks)
+		admin.DELETE("/shares/:id", handlers.RevokeShareLink)
+	}
+	return r
+}
+
+func TestDocumentShareLinks(t *testing.T) {
+	useStubScanner(t)
+	router := setupShareLinkRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Shared Co",
+		"joiningDate": "2024-01-01",
+		"department":  "Legal",
+		"projectName": "Audit",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	w = uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+		"vendorId": vendor.ID, "type": "agreement",
+	}, "audit.pdf", []byte("%PDF-1.4 audit copy"))
+	var doc models.Document
+	json.Unmarshal(w.Body.Bytes(), &doc)
+
+	share := func(body map[string]interface{}) (string, string) {
+		w := doJSON(router, "POST", "/api/admin/documents/"+doc.ID+"/share", token, body)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var resp struct {
+			Link models.ShareLink `json:"link"`
+			URL  string           `json:"url"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		shared, _ := url.Parse(resp.URL)
+		return resp.Link.ID, shared.RequestURI()
+	}
+	download := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w = doJSON(router, "POST", "/api/admin/documents/"+doc.ID+"/share", token, map[string]int{"expiresInHours": 24 * 365})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// A single-use link works once without a token
+	linkID, path := share(map[string]interface{}{"singleUse": true})
+	w = download(path)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "%PDF-1.4 audit copy", w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "audit.pdf")
+	w = download(path)
+	assert.Equal(t, http.StatusGone, w.Code)
+
+	// Tampering with the expiry breaks the signature
+	_, path = share(map[string]interface{}{})
+	shared, _ := url.Parse(path)
+	query := shared.Query()
+	query.Set("expires", "4102444800")
+	w = download(shared.Path + "?" + query.Encode())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = download(shared.Path)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	otherID, path := share(map[string]interface{}{"maxDownloads": 5})
+	w = doJSON(router, "DELETE", "/api/admin/shares/"+otherID, token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = download(path)
+	assert.Equal(t, http.StatusGone, w.Code)
+
+	// Every request is kept for audit
+	w = doJSON(router, "GET", "/api/admin/documents/"+doc.ID+"/shares", token, nil)
+	var links []struct {
+		Link     models.ShareLink         `json:"link"`
+		Accesses []models.ShareLinkAccess `json:"accesses"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &links)
+	assert.Len(t, links, 3)
+	for _, entry := range links {
+		if entry.Link.ID == linkID {
+			assert.Equal(t, 1, entry.Link.Downloads)
+			if assert.Len(t, entry.Accesses, 2) {
+				assert.Equal(t, "served", entry.Accesses[0].Outcome)
+				assert.Equal(t, "exhausted", entry.Accesses[1].Outcome)
+			}
+		}
+		if entry.Link.ID == otherID && assert.Len(t, entry.Accesses, 1) {
+			assert.Equal(t, "revoked", entry.Accesses[0].Outcome)
+		}
+	}
+}
+
+func TestShareLinkCountsCompleteDownloads(t *testing.T) {
+	useStubScanner(t)
+	router := setupShareLinkRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Partial Co",
+		"joiningDate": "2024-01-01",
+		"department":  "Legal",
+		"projectName": "Audit",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	upload := func(name, content string) *models.Document {
+		w := uploadDocument(t, router, "/api/admin/documents", token, map[string]string{
+			"vendorId": vendor.ID, "type": "agreement",
+		}, name, []byte(content))
+		var doc models.Document
+		json.Unmarshal(w.Body.Bytes(), &doc)
+		return models.Documents[doc.ID]
+	}
+	share := func(doc *models.Document, singleUse bool) (*models.ShareLink, string) {
+		w := doJSON(router, "POST", "/api/admin/documents/"+doc.ID+"/share", token, map[string]bool{"singleUse": singleUse})
+		var resp struct {
+			Link models.ShareLink `json:"link"`
+			URL  string           `json:"url"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		shared, _ := url.Parse(resp.URL)
+		return models.ShareLinks[resp.Link.ID], shared.RequestURI()
+	}
+	download := func(path, byteRange string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		if byteRange != "" {
+			req.Header.Set("Range", byteRange)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	outcomes := func(link *models.ShareLink) []string {
+		result := make([]string, 0)
+		for _, access := range models.ShareLinkAccesses[link.ID] {
+			result = append(result, access.Outcome)
+		}
+		return result
+	}
+
+	// A single-use link ignores Range and sends the whole file once
+	doc := upload("ranged.pdf", "%PDF-1.4 ranged copy")
+	link, path := share(doc, true)
+	w = download(path, "bytes=0-")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "%PDF-1.4 ranged copy", w.Body.String())
+	assert.Equal(t, 1, link.Downloads)
+	w = download(path, "bytes=0-7")
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.Equal(t, []string{"served", "exhausted"}, outcomes(link))
+
+	// Links without a limit serve ranges, each counted as a download
+	link, path = share(doc, false)
+	w = download(path, "bytes=0-7")
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "%PDF-1.4", w.Body.String())
+	assert.Equal(t, 1, link.Downloads)
+	assert.Equal(t, []string{"served_range"}, outcomes(link))
+
+	// Of parallel requests for a single-use link only one gets the file
+	link, path = share(doc, true)
+	codes := make(chan int, 10)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(codes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- download(path, "").Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+	counts := make(map[int]int)
+	for code := range codes {
+		counts[code]++
+	}
+	assert.Equal(t, map[int]int{http.StatusOK: 1, http.StatusGone: 9}, counts)
+	assert.Equal(t, 1, link.Downloads)
+
+	// A download that fails is not counted
+	missing := upload("missing.pdf", "%PDF-1.4 missing copy")
+	storage.Store.Delete(missing.StorageKey)
+	link, path = share(missing, true)
+	w = download(path, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, 0, link.Downloads)
+	assert.Equal(t, []string{"failed_404"}, outcomes(link))
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"os"
+	"testing"
+	"vendor-management/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exerciseBlobStore checks the BlobStore contract against a backend
+func exerciseBlobStore(t *testing.T, store storage.BlobStore) {
+	key := "test/storage/hello world.txt"
+	content := []byte("hello, storage backend")
+	defer store.Delete(key)
+
+	if !assert.NoError(t, store.Put(key, bytes.NewReader(content), int64(len(content)))) {
+		return
+	}
+
+	obj, err := store.Open(key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(len(content)), obj.Size())
+	data, _ := io.ReadAll(obj)
+	assert.Equal(t, content, data)
+
+	// Seeking supports range requests
+	obj.Seek(7, io.SeekStart)
+	data, _ = io.ReadAll(obj)
+	assert.Equal(t, "storage backend", string(data))
+	obj.Close()
+
+	keys, err := store.List("test/storage/")
+	assert.NoError(t, err)
+	assert.Contains(t, keys, key)
+
+	assert.NoError(t, store.Delete(key))
+	_, err = store.Open(key)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.NoError(t, store.Delete(key))
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	if !assert.NoError(t, err) {
+		return
+	}
+	exerciseBlobStore(t, store)
+
+	assert.Error(t, store.Put("../outside.txt", bytes.NewReader(nil), 0))
+}
+
+// TestS3Store runs against an S3-compatible server such as a local MinIO:
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	S3_TEST_ENDPOINT=http://localhost:9000 S3_TEST_BUCKET=test go test ./tests -run TestS3Store
+func TestS3Store(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT not set")
+	}
+	accessKey, secretKey := os.Getenv("S3_TEST_ACCESS_KEY"), os.Getenv("S3_TEST_SECRET_KEY")
+	if accessKey == "" {
+		accessKey, secretKey = "minioadmin", "minioadmin"
+	}
+
+	store, err := storage.NewS3Store(storage.S3Config{
+		Endpoint:  endpoint,
+		Region:    "us-east-1",
+		Bucket:    os.Getenv("S3_TEST_BUCKET"),
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		PathStyle: true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	exerciseBlobStore(t, store)
+
+	// Files migrate between backends under the same keys
+	local, _ := storage.NewLocalStore(t.TempDir())
+	local.Put("test/migrate/a.txt", bytes.NewReader([]byte("a")), 1)
+	defer store.Delete("test/migrate/a.txt")
+	copied, err := storage.Migrate(local, store, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, copied)
+	keys, _ := local.List("")
+	assert.Empty(t, keys)
+}
+
+func TestEncryptedStore(t *testing.T) {
+	oldKey := "2024-01:" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	newKey := "2025-06:" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	keys, err := storage.ParseKeyRing(oldKey, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	inner, _ := storage.NewLocalStore(t.TempDir())
+	store := storage.NewEncryptedStore(inner, keys)
+	exerciseBlobStore(t, store)
+
+	// Files span several chunks and nothing readable reaches the backend
+	content := bytes.Repeat([]byte("confidential "), 20000)
+	assert.NoError(t, store.Put("documents/v1/id.pdf", bytes.NewReader(content), int64(len(content))))
+	raw, _ := inner.Open("documents/v1/id.pdf")
+	sealed, _ := io.ReadAll(raw)
+	raw.Close()
+	assert.NotContains(t, string(sealed), "confidential")
+
+	obj, err := store.Open("documents/v1/id.pdf")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(len(content)), obj.Size())
+	obj.Seek(100000, io.SeekStart)
+	data, _ := io.ReadAll(obj)
+	obj.Close()
+	assert.Equal(t, content[100000:], data)
+
+	// Files stored before encryption was enabled are read as they are
+	inner.Put("documents/v1/legacy.pdf", bytes.NewReader([]byte("plain")), 5)
+	obj, _ = store.Open("documents/v1/legacy.pdf")
+	data, _ = io.ReadAll(obj)
+	obj.Close()
+	assert.Equal(t, "plain", string(data))
+
+	// Rotation rewraps data keys without touching the files
+	rotated, _ := storage.ParseKeyRing(newKey, oldKey)
+	rewrapped, err := storage.NewEncryptedStore(inner, rotated).Rewrap()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rewrapped)
+	raw, _ = inner.Open("documents/v1/id.pdf")
+	after, _ := io.ReadAll(raw)
+	raw.Close()
+	assert.Equal(t, sealed, after)
+
+	newOnly, _ := storage.ParseKeyRing(newKey, "")
+	obj, err = storage.NewEncryptedStore(inner, newOnly).Open("documents/v1/id.pdf")
+	if assert.NoError(t, err) {
+		data, _ = io.ReadAll(obj)
+		obj.Close()
+		assert.Equal(t, content, data)
+	}
+	_, err = store.Open("documents/v1/id.pdf")
+	assert.Error(t, err)
+
+	_, err = storage.ParseKeyRing("2024-01:short", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+	"vendor-management/models"
+	"vendor-management/storage"
+	"vendor-management/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTrashRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.GET("/vendors/:id", handlers.GetVendor)
+		admin.DELETE("/vendors/:id", handlers.DeleteVendor)
+		admin.POST("/vendors/:id/restore", handlers.RestoreVendor)
+		admin.POST("/assets", handlers.CreateAsset)
+		admin.GET("/assets", handlers.ListAssets)
+		admin.DELETE("/assets/:id", handlers.DeleteAsset)
+		admin.POST("/assets/:id/restore", handlers.RestoreAsset)
+		admin.GET("/trash", handlers.ListTrash)
+	}
+	return r
+}
+
+func TestSoftDeleteRestoreAndPurge(t *testing.T) {
+	router := setupTrashRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/assets", token, map[string]string{"name": "Trash Monitor", "type": "monitor"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var asset models.Asset
+	json.Unmarshal(w.Body.Bytes(), &asset)
+
+	// Deleted assets disappear from the list and appear in the trash
+	w = doJSON(router, "DELETE", "/api/admin/assets/"+asset.ID, token, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doJSON(router, "GET", "/api/admin/assets", token, nil)
+	assert.NotContains(t, w.Body.String(), asset.ID)
+	w = doJSON(router, "GET", "/api/admin/trash", token, nil)
+	assert.Contains(t, w.Body.String(), asset.ID)
+
+	// Restoring brings it back
+	w = doJSON(router, "POST", "/api/admin/assets/"+asset.ID+"/restore", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(router, "GET", "/api/admin/assets", token, nil)
+	assert.Contains(t, w.Body.String(), asset.ID)
+
+	w = doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Trash Co",
+		"joiningDate": "2024-03-01",
+		"department":  "Ops",
+		"projectName": "Cleanup",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+
+	w = doJSON(router, "DELETE", "/api/admin/vendors/"+vendor.ID, token, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doJSON(router, "GET", "/api/admin/vendors/"+vendor.ID, token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Restoring honours If-Match like deleting does
+	req, _ := http.NewRequest("POST", "/api/admin/vendors/"+vendor.ID+"/restore", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.NotNil(t, models.Vendors[vendor.ID].DeletedAt)
+
+	// Recently deleted records survive the purge; expired ones do not
+	utils.PurgeTrash()
+	assert.Contains(t, models.Vendors, vendor.ID)
+
+	models.ProjectAssignments[vendor.ID] = []*models.ProjectAssignment{{VendorID: vendor.ID}}
+	models.PerformanceReviews[vendor.ID] = []*models.PerformanceReview{{VendorID: vendor.ID}}
+	models.RateCards[vendor.ID] = []*models.RateCard{{VendorID: vendor.ID}}
+	models.ComplianceStatuses[vendor.ID] = &models.ComplianceStatus{}
+	expired := time.Now().AddDate(-1, 0, 0)
+	models.Vendors[vendor.ID].DeletedAt = &expired
+	utils.PurgeTrash()
+	assert.NotContains(t, models.Vendors, vendor.ID)
+	assert.NotContains(t, models.VendorRevisions, vendor.ID)
+	assert.NotContains(t, models.ProjectAssignments, vendor.ID)
+	assert.NotContains(t, models.PerformanceReviews, vendor.ID)
+	assert.NotContains(t, models.RateCards, vendor.ID)
+	assert.NotContains(t, models.ComplianceStatuses, vendor.ID)
+}
+
+// failingDeleteStore fails to delete one key, as a storage backend might
+type failingDeleteStore struct {
+	storage.BlobStore
+	key string
+}
+
+func (s failingDeleteStore) Delete(key string) error {
+	if key == s.key {
+		return errors.New("storage unavailable")
+	}
+	return s.BlobStore.Delete(key)
+}
+
+func TestPurgeKeepsVendorWhenFileRemovalFails(t *testing.T) {
+	expired := time.Now().AddDate(-1, 0, 0)
+	vendor := &models.Vendor{ID: "purge-vendor", CompanyName: "Stuck Co", DeletedAt: &expired}
+	models.Vendors[vendor.ID] = vendor
+	stuck := &models.Document{ID: "purge-stuck", VendorID: vendor.ID, StorageKey: "documents/purge-vendor/stuck.pdf"}
+	removable := &models.Document{ID: "purge-removable", VendorID: vendor.ID, StorageKey: "documents/purge-vendor/removable.pdf"}
+	models.Documents[stuck.ID] = stuck
+	models.Documents[removable.ID] = removable
+
+	store := storage.Store
+	storage.Store = failingDeleteStore{BlobStore: store, key: stuck.StorageKey}
+	utils.PurgeTrash()
+	storage.Store = store
+
+	// The vendor waits for the document that could not be removed
+	assert.Contains(t, models.Vendors, vendor.ID)
+	assert.Contains(t, models.Documents, stuck.ID)
+	assert.NotContains(t, models.Documents, removable.ID)
+
+	utils.PurgeTrash()
+	assert.NotContains(t, models.Vendors, vendor.ID)
+	assert.NotContains(t, models.Documents, stuck.ID)
+}
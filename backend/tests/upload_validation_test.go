@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"vendor-management/config"
+	"vendor-management/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// docxContent builds a minimal Word document archive with one paragraph
+// per line of text
+func docxContent(lines ...string) []byte {
+	var body strings.Builder
+	body.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+	for _, line := range lines {
+		body.WriteString("<w:p><w:r><w:t>" + line + "</w:t></w:r></w:p>")
+	}
+	body.WriteString("</w:body></w:document>")
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	entry, _ := archive.Create("[Content_Types].xml")
+	entry.Write([]byte("<Types/>"))
+	entry, _ = archive.Create("word/document.xml")
+	entry.Write([]byte(body.String()))
+	archive.Close()
+	return buf.Bytes()
+}
+
+func TestUploadValidation(t *testing.T) {
+	router := setupDocumentVersionRouter()
+	token := loginAdmin(t, router)
+
+	w := doJSON(router, "POST", "/api/admin/vendors", token, map[string]string{
+		"companyName": "Uploads Co",
+		"joiningDate": "2024-01-01",
+		"department":  "Legal",
+		"projectName": "Contracts",
+	})
+	var vendor models.Vendor
+	json.Unmarshal(w.Body.Bytes(), &vendor)
+	fields := func(docType string) map[string]string {
+		return map[string]string{"vendorId": vendor.ID, "type": docType}
+	}
+
+	w = uploadDocument(t, router, "/api/admin/documents", token, fields("contract"), "a.pdf", []byte("%PDF-1.4"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Formats are sniffed from the content, not trusted from the name
+	w = uploadDocument(t, router, "/api/admin/documents", token, fields("agreement"), "a.pdf", []byte("plain text"))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	w = uploadDocument(t, router, "/api/admin/documents", token, fields("agreement"), "a.png", []byte("%PDF-1.4"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	w = uploadDocument(t, router, "/api/admin/documents", token, fields("agreement"), "scan.png", png)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	w = uploadDocument(t, router, "/api/admin/documents", token, fields("id_proof"), "scan.png", png)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = uploadDocument(t, router, "/api/admin/documents", token, fields("nda"), "nda.docx", docxContent())
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var doc models.Document
+	json.Unmarshal(w.Body.Bytes(), &doc)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", doc.ContentType)
+
+	oversized := append([]byte("%PDF-1.4"), make([]byte, config.MaxUploadSize)...)
+	w = uploadDocument(t, router, "/api/admin/documents", token, fields("agreement"), "big.pdf", oversized)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
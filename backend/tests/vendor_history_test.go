@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+	"vendor-management/handlers"
+	"vendor-management/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupHistoryRouter() *gin.Engine {
+	r := gin.Default()
+	r.POST("/api/auth/login", handlers.HandleLogin)
+
+	api := r.Group("/api")
+	api.Use(middleware.AuthMiddleware())
+	admin := api.Group("/admin")
+	admin.Use(middleware.AdminMiddleware())
+	{
+		admin.POST("/vendors", handlers.CreateVendor)
+		admin.GET("/vendors/:id", handlers.GetVendor)
+		admin.PUT("/vendors/:id", handlers.UpdateVendor)
+		admin.GET("/vendors/:id/history", handlers.GetVendorHistory)
+	}
+	return r
+}
+
+func TestVendorHistoryAndAsOf(t *testing.T) {
+	router := setupHistoryRouter()
+	token := loginAdmin(t, router)
+
+	vendor := map[string]string{
+		"companyName": "History Co",
+		"joiningDate": "2024-01-01",
+		"department":  "IT",
+		"projectName": "Apollo",
+	}
+	w := doJSON(router, "POST", "/api/admin/vendors", token, vendor)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	id := created["id"].(string)
+
+	beforeUpdate := time.Now()
+	time.Sleep(time.Millisecond)
+
+	vendor["projectName"] = "Gemini"
+	w = doJSON(router, "PUT", "/api/admin/vendors/"+id, token, vendor)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// History has one entry per revision with field-level diffs
+	w = doJSON(router, "GET", "/api/admin/vendors/"+id+"/history", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var history []handlers.VendorHistoryEntry
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, []handlers.FieldChange{{Field: "projectName", From: "Apollo", To: "Gemini"}}, history[1].Changes)
+		assert.NotEmpty(t, history[1].ChangedBy)
+	}
+
+	// asOf reconstructs the record before the update
+	asOf := url.QueryEscape(beforeUpdate.Format(time.RFC3339Nano))
+	w = doJSON(router, "GET", "/api/admin/vendors/"+id+"?asOf="+asOf, token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Vendor struct {
+			ProjectName string `json:"projectName"`
+		} `json:"vendor"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "Apollo", resp.Vendor.ProjectName)
+
+	// Before the vendor existed there is nothing to reconstruct
+	w = doJSON(router, "GET", "/api/admin/vendors/"+id+"?asOf=2000-01-01", token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doJSON(router, "GET", "/api/admin/vendors/"+id+"?asOf=yesterday", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
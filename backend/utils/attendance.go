@@ -16,7 +16,7 @@ func GenerateRandomAttendance(vendorID string) []*models.Attendance {
 	// Generate attendance for last 5 days
 	for i := 1; i <= 5; i++ {
 		date := now.AddDate(0, 0, -i)
-		status := generateRandomStatus()
+		presentDay := generateRandomStatus()
 
 		// Generate random login time between 8:00 AM and 10:00 AM
 		loginTime := time.Date(date.Year(), date.Month(), date.Day(), 8+rand.Intn(3), rand.Intn(60), 0, 0, time.Local)
@@ -24,8 +24,10 @@ func GenerateRandomAttendance(vendorID string) []*models.Attendance {
 		// Generate random logout time between 5:00 PM and 7:00 PM
 		logoutTime := time.Date(date.Year(), date.Month(), date.Day(), 17+rand.Intn(3), rand.Intn(60), 0, 0, time.Local)
 
-		// Calculate duration in hours
-		duration := logoutTime.Sub(loginTime).Hours()
+		status := "Present"
+		if presentDay == 0 {
+			status = "Absent"
+		}
 
 		attendance = append(attendance, &models.Attendance{
 			ID:         generateID(),
@@ -33,7 +35,7 @@ func GenerateRandomAttendance(vendorID string) []*models.Attendance {
 			Date:       date,
 			LoginTime:  loginTime,
 			LogoutTime: logoutTime,
-			Duration:   float32(duration),
+			PresentDay: presentDay,
 			Status:     status,
 		})
 	}
@@ -67,7 +69,7 @@ func generateID() string {
 // UpdateAttendance updates attendance for all vendors
 func UpdateAttendance() {
 	for _, vendor := range models.Vendors {
-		if vendor.Status == "active" {
+		if vendor.Status == "active" && vendor.DeletedAt == nil {
 			// Get existing attendance records for vendor
 			existingAttendance := models.AttendanceRecords[vendor.ID]
 			if existingAttendance == nil {
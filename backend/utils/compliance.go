@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"vendor-management/config"
+	"vendor-management/models"
+)
+
+// ComplianceHook is called when the compliance check suspends or reinstates
+// a vendor, after the vendor's status has been updated
+type ComplianceHook func(vendor *models.Vendor, status *models.ComplianceStatus)
+
+var (
+	suspensionHooks    []*ComplianceHook
+	reinstatementHooks []*ComplianceHook
+)
+
+// OnVendorSuspended registers a hook run whenever a vendor is suspended for
+// lacking a critical document. Calling the returned function removes it.
+func OnVendorSuspended(hook ComplianceHook) (remove func()) {
+	return addComplianceHook(&suspensionHooks, hook)
+}
+
+// OnVendorReinstated registers a hook run whenever a vendor suspended by the
+// compliance check has its critical documents in order again. Calling the
+// returned function removes it.
+func OnVendorReinstated(hook ComplianceHook) (remove func()) {
+	return addComplianceHook(&reinstatementHooks, hook)
+}
+
+func addComplianceHook(hooks *[]*ComplianceHook, hook ComplianceHook) func() {
+	registered := &hook
+	*hooks = append(*hooks, registered)
+	return func() {
+		for i, other := range *hooks {
+			if other == registered {
+				*hooks = append((*hooks)[:i:i], (*hooks)[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// DocumentValidOn reports whether the document is in force on the date. The
+// expiry date itself is the last valid day.
+func DocumentValidOn(doc *models.Document, date time.Time) bool {
+	if !doc.ValidFrom.IsZero() && date.Before(doc.ValidFrom) {
+		return false
+	}
+	return doc.ExpiresAt.IsZero() || date.Before(doc.ExpiresAt.AddDate(0, 0, 1))
+}
+
+// ComplianceRulesFor returns the rules that apply to vendors of the type
+func ComplianceRulesFor(vendorType string) []*models.ComplianceRule {
+	rules := make([]*models.ComplianceRule, 0)
+	for _, rule := range models.ComplianceRules {
+		if rule.VendorType == "" || strings.EqualFold(rule.VendorType, vendorType) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// EvaluateCompliance checks the vendor's documents against the rules for its
+// type. It also reports whether a critical document is missing or expired.
+func EvaluateCompliance(vendor *models.Vendor, now time.Time) (*models.ComplianceStatus, bool) {
+	status := &models.ComplianceStatus{
+		VendorID:  vendor.ID,
+		State:     models.Compliant,
+		Missing:   make([]string, 0),
+		Expired:   make([]string, 0),
+		Expiring:  make([]string, 0),
+		CheckedAt: now,
+	}
+
+	criticalLapse := false
+	for _, rule := range ComplianceRulesFor(vendor.Type) {
+		// Documents that only take effect in the future, and vendor uploads not
+		// yet approved, count as not provided
+		provided, valid, neverExpires := false, false, false
+		var latestExpiry time.Time
+		for _, doc := range models.Documents {
+			if doc.VendorID != vendor.ID || doc.DeletedAt != nil || doc.Type != rule.DocumentType ||
+				doc.Status != models.DocumentApproved {
+				continue
+			}
+			if !doc.ValidFrom.IsZero() && now.Before(doc.ValidFrom) {
+				continue
+			}
+			provided = true
+			if !DocumentValidOn(doc, now) {
+				continue
+			}
+			valid = true
+			if doc.ExpiresAt.IsZero() {
+				neverExpires = true
+			} else if doc.ExpiresAt.After(latestExpiry) {
+				latestExpiry = doc.ExpiresAt
+			}
+		}
+
+		switch {
+		case !provided:
+			status.Missing = append(status.Missing, string(rule.DocumentType))
+		case !valid:
+			status.Expired = append(status.Expired, string(rule.DocumentType))
+		case !neverExpires && latestExpiry.Before(now.Add(config.ComplianceWarning)):
+			status.Expiring = append(status.Expiring, string(rule.DocumentType))
+		}
+		if rule.Critical && (!provided || !valid) {
+			criticalLapse = true
+		}
+	}
+
+	if len(status.Missing) > 0 || len(status.Expired) > 0 {
+		status.State = models.NonCompliant
+	} else if len(status.Expiring) > 0 {
+		status.State = models.Expiring
+	}
+	return status, criticalLapse
+}
+
+// CheckVendorCompliance re-evaluates one vendor, suspending it when a
+// critical document has lapsed and reinstating it once the documents are in
+// order again. Only vendors suspended by this check are reinstated.
+func CheckVendorCompliance(vendor *models.Vendor) *models.ComplianceStatus {
+	status, criticalLapse := EvaluateCompliance(vendor, time.Now())
+	previous := models.ComplianceStatuses[vendor.ID]
+	wasSuspended := previous != nil && previous.Suspended
+
+	switch {
+	case criticalLapse && vendor.Status == "active":
+		setVendorStatus(vendor, "suspended")
+		status.Suspended = true
+		lapsed := make([]string, 0, len(status.Missing)+len(status.Expired))
+		lapsed = append(append(lapsed, status.Missing...), status.Expired...)
+		NotifyAdmins("compliance_suspended", vendor.ID, fmt.Sprintf("%s suspended: required documents missing or expired (%s)",
+			vendor.CompanyName, strings.Join(lapsed, ", ")))
+		for _, hook := range suspensionHooks {
+			(*hook)(vendor, status)
+		}
+	case wasSuspended && criticalLapse:
+		status.Suspended = vendor.Status == "suspended"
+	case wasSuspended && vendor.Status == "suspended":
+		setVendorStatus(vendor, "active")
+		NotifyAdmins("compliance_reinstated", vendor.ID, vendor.CompanyName+" reinstated: critical documents are in order")
+		for _, hook := range reinstatementHooks {
+			(*hook)(vendor, status)
+		}
+	}
+
+	if status.State == models.Expiring && (previous == nil || previous.State != models.Expiring) {
+		NotifyAdmins("compliance_expiring", vendor.ID, fmt.Sprintf("Documents for %s expire soon (%s)",
+			vendor.CompanyName, strings.Join(status.Expiring, ", ")))
+	}
+
+	models.ComplianceStatuses[vendor.ID] = status
+	return status
+}
+
+func setVendorStatus(vendor *models.Vendor, status string) {
+	vendor.Status = status
+	vendor.Version++
+	RecordVendorRevision(vendor, "")
+}
+
+// CheckCompliance re-evaluates every vendor that is not inactive or deleted
+func CheckCompliance() {
+	nonCompliant := 0
+	for _, vendor := range models.Vendors {
+		if vendor.DeletedAt != nil || vendor.Status == "inactive" {
+			continue
+		}
+		if CheckVendorCompliance(vendor).State == models.NonCompliant {
+			nonCompliant++
+		}
+	}
+	log.Printf("Compliance check complete: %d non-compliant vendors", nonCompliant)
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"time"
+	"vendor-management/models"
+	"vendor-management/storage"
+)
+
+// ErrChecksumMismatch is returned when a stored file no longer matches the
+// checksum taken at upload
+var ErrChecksumMismatch = errors.New("File failed integrity check")
+
+// FileChecksum returns the hex SHA-256 digest of everything read from r
+func FileChecksum(r io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyChecksum reads an object to the end, compares it with the expected
+// checksum and rewinds it. Files uploaded before checksums were taken have
+// none and always pass.
+func VerifyChecksum(obj io.ReadSeeker, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+	actual, err := FileChecksum(obj)
+	if err != nil {
+		return err
+	}
+	if actual != checksum {
+		return ErrChecksumMismatch
+	}
+	_, err = obj.Seek(0, io.SeekStart)
+	return err
+}
+
+// checkStoredFile opens one stored file and verifies its contents
+func checkStoredFile(key, checksum string) error {
+	obj, err := storage.Store.Open(key)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	return VerifyChecksum(obj, checksum)
+}
+
+// ScanDocumentIntegrity verifies the file of every version of every document,
+// including documents in the trash whose files are still kept, and alerts
+// admins to missing or corrupted files
+func ScanDocumentIntegrity() *models.IntegrityReport {
+	report := &models.IntegrityReport{CheckedAt: time.Now(), Issues: make([]models.IntegrityIssue, 0)}
+
+	for id, doc := range models.Documents {
+		for _, version := range doc.Versions {
+			// Quarantined files are dealt with separately and may be destroyed
+			if version.ScanStatus == models.ScanInfected {
+				continue
+			}
+			report.Files++
+			err := checkStoredFile(version.StorageKey, version.Checksum)
+			if err == nil {
+				continue
+			}
+
+			issue := models.IntegrityIssue{DocumentID: id, Version: version.Version, StorageKey: version.StorageKey}
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				issue.Problem = models.FileMissing
+			case errors.Is(err, ErrChecksumMismatch):
+				issue.Problem = models.FileCorrupted
+			default:
+				// Unreadable files may be a passing storage outage, so they are
+				// logged rather than reported
+				log.Printf("Failed to check file %s of document %s: %v", version.StorageKey, id, err)
+				continue
+			}
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	sort.Slice(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.DocumentID != b.DocumentID {
+			return a.DocumentID < b.DocumentID
+		}
+		return a.Version < b.Version
+	})
+
+	models.LastIntegrityReport = report
+	if len(report.Issues) > 0 {
+		NotifyAdmins("integrity_issues", "", fmt.Sprintf("%d of %d document files are missing or corrupted", len(report.Issues), report.Files))
+	}
+	log.Printf("Checked %d document files, found %d missing or corrupted", report.Files, len(report.Issues))
+	return report
+}
+
+// RunIntegrityScan is the cron entry point for ScanDocumentIntegrity
+func RunIntegrityScan() {
+	ScanDocumentIntegrity()
+}
@@ -0,0 +1,257 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+	"vendor-management/config"
+	"vendor-management/models"
+)
+
+// InvoiceRunResult reports the invoices produced by an invoice run and the
+// vendors that could not be invoiced
+type InvoiceRunResult struct {
+	PeriodStart time.Time         `json:"periodStart"`
+	PeriodEnd   time.Time         `json:"periodEnd"`
+	Invoices    []*models.Invoice `json:"invoices"`
+	Skipped     []InvoiceRunSkip  `json:"skipped"`
+}
+
+type InvoiceRunSkip struct {
+	VendorID string `json:"vendorId"`
+	Reason   string `json:"reason"`
+}
+
+// calendarDay returns midnight UTC of the date as seen in its own location.
+// Rate card dates are parsed that way, so attendance recorded at any time of
+// day in any location compares by calendar day.
+func calendarDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// RateCardCovers reports whether the rate card is in effect on the calendar
+// day of the date. EffectiveTo is the card's last day, inclusive.
+func RateCardCovers(card *models.RateCard, date time.Time) bool {
+	day := calendarDay(date)
+	if day.Before(calendarDay(card.EffectiveFrom)) {
+		return false
+	}
+	return card.EffectiveTo.IsZero() || !day.After(calendarDay(card.EffectiveTo))
+}
+
+// EffectiveRateCard returns the rate card in effect for a vendor on the date.
+// The vendor's own rate cards take precedence over its company's.
+func EffectiveRateCard(vendor *models.Vendor, date time.Time) (*models.RateCard, bool) {
+	for _, ownerID := range []string{vendor.ID, vendor.CompanyID} {
+		if ownerID == "" {
+			continue
+		}
+		for _, card := range models.RateCards[ownerID] {
+			if RateCardCovers(card, date) {
+				return card, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// RoundMoney rounds an amount to two decimal places
+func RoundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// workingDays counts the weekdays in the period, used to prorate monthly rates
+func workingDays(start, end time.Time) int {
+	days := 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			days++
+		}
+	}
+	return days
+}
+
+// overtimeHours returns the hours worked beyond a standard day on a full day
+func overtimeHours(record *models.Attendance) float64 {
+	if record.PresentDay < 1 || record.LoginTime.IsZero() || record.LogoutTime.IsZero() {
+		return 0
+	}
+	worked := record.LogoutTime.Sub(record.LoginTime).Hours()
+	return math.Max(0, worked-float64(config.StandardWorkHours))
+}
+
+type rateBucket struct {
+	card     *models.RateCard
+	days     float64
+	overtime float64
+}
+
+// buildInvoice computes the line items, taxes and totals for one vendor's
+// attendance in the period. It returns an error describing why the vendor
+// cannot be invoiced.
+func buildInvoice(vendor *models.Vendor, periodStart, periodEnd time.Time) (*models.Invoice, error) {
+	buckets := make([]*rateBucket, 0)
+	byCard := make(map[string]*rateBucket)
+	for _, record := range models.AttendanceRecords[vendor.ID] {
+		if record.Date.Before(periodStart) || record.Date.After(periodEnd) || record.PresentDay <= 0 {
+			continue
+		}
+		card, ok := EffectiveRateCard(vendor, record.Date)
+		if !ok {
+			return nil, fmt.Errorf("No rate card in effect on %s", record.Date.Format("2006-01-02"))
+		}
+		bucket, ok := byCard[card.ID]
+		if !ok {
+			bucket = &rateBucket{card: card}
+			byCard[card.ID] = bucket
+			buckets = append(buckets, bucket)
+		}
+		bucket.days += float64(record.PresentDay)
+		bucket.overtime += overtimeHours(record)
+	}
+	if len(buckets) == 0 {
+		return nil, fmt.Errorf("No billable attendance")
+	}
+
+	currency := buckets[0].card.Currency
+	standardHours := float64(config.StandardWorkHours)
+	monthDays := float64(workingDays(periodStart, periodEnd))
+
+	invoice := &models.Invoice{
+		VendorID:     vendor.ID,
+		CompanyID:    vendor.CompanyID,
+		ProjectID:    vendor.ProjectID,
+		DepartmentID: vendor.DepartmentID,
+		PeriodStart:  periodStart,
+		PeriodEnd:    periodEnd,
+		Currency:     currency,
+		LineItems:    make([]models.InvoiceLineItem, 0),
+		Taxes:        make([]models.InvoiceTax, 0),
+		Status:       models.InvoiceDraft,
+	}
+	for _, bucket := range buckets {
+		card := bucket.card
+		if card.Currency != currency {
+			return nil, fmt.Errorf("Rate cards in the period use different currencies")
+		}
+
+		var item models.InvoiceLineItem
+		var hourlyRate float64
+		switch card.Unit {
+		case "hourly":
+			item = models.InvoiceLineItem{Quantity: bucket.days * standardHours, Unit: "hour"}
+			hourlyRate = card.Rate
+		case "daily":
+			item = models.InvoiceLineItem{Quantity: bucket.days, Unit: "day"}
+			hourlyRate = card.Rate / standardHours
+		case "monthly":
+			item = models.InvoiceLineItem{Quantity: math.Round(bucket.days/monthDays*10000) / 10000, Unit: "month"}
+			hourlyRate = card.Rate / (monthDays * standardHours)
+		}
+		item.Description = fmt.Sprintf("Services at %s rate (%.1f days)", card.Unit, bucket.days)
+		item.RateCardID = card.ID
+		item.UnitPrice = card.Rate
+		item.Amount = RoundMoney(item.Quantity * item.UnitPrice)
+		invoice.LineItems = append(invoice.LineItems, item)
+
+		if bucket.overtime > 0 {
+			overtime := models.InvoiceLineItem{
+				Description: fmt.Sprintf("Overtime at %gx", card.OvertimeMultiplier),
+				RateCardID:  card.ID,
+				Quantity:    math.Round(bucket.overtime*100) / 100,
+				Unit:        "hour",
+				UnitPrice:   RoundMoney(hourlyRate * card.OvertimeMultiplier),
+			}
+			overtime.Amount = RoundMoney(overtime.Quantity * overtime.UnitPrice)
+			invoice.LineItems = append(invoice.LineItems, overtime)
+		}
+	}
+
+	for _, item := range invoice.LineItems {
+		invoice.Subtotal += item.Amount
+	}
+	invoice.Subtotal = RoundMoney(invoice.Subtotal)
+
+	total := invoice.Subtotal
+	for _, rule := range models.TaxRules {
+		if rule.Currency != "" && rule.Currency != currency {
+			continue
+		}
+		tax := models.InvoiceTax{
+			Name:   rule.Name,
+			Rate:   rule.Rate,
+			Amount: RoundMoney(invoice.Subtotal * rule.Rate / 100),
+		}
+		invoice.Taxes = append(invoice.Taxes, tax)
+		total += tax.Amount
+	}
+	invoice.Total = RoundMoney(total)
+	return invoice, nil
+}
+
+// GenerateInvoices creates a draft invoice for every vendor with billable
+// attendance in the month containing the given date. Existing drafts for the
+// month are recalculated; approved and paid invoices are left untouched.
+func GenerateInvoices(month time.Time, createdBy string) InvoiceRunResult {
+	periodStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	periodEnd := periodStart.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	result := InvoiceRunResult{
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Invoices:    make([]*models.Invoice, 0),
+		Skipped:     make([]InvoiceRunSkip, 0),
+	}
+
+	existing := make(map[string]*models.Invoice)
+	sequence := 0
+	for _, invoice := range models.Invoices {
+		if invoice.PeriodStart.Equal(periodStart) {
+			existing[invoice.VendorID] = invoice
+			sequence++
+		}
+	}
+
+	for _, vendor := range models.Vendors {
+		if vendor.DeletedAt != nil {
+			continue
+		}
+
+		previous, exists := existing[vendor.ID]
+		if exists && previous.Status != models.InvoiceDraft {
+			result.Skipped = append(result.Skipped, InvoiceRunSkip{VendorID: vendor.ID, Reason: "Invoice already " + string(previous.Status)})
+			continue
+		}
+
+		invoice, err := buildInvoice(vendor, periodStart, periodEnd)
+		if err != nil {
+			result.Skipped = append(result.Skipped, InvoiceRunSkip{VendorID: vendor.ID, Reason: err.Error()})
+			continue
+		}
+
+		if exists {
+			invoice.ID = previous.ID
+			invoice.Number = previous.Number
+		} else {
+			sequence++
+			invoice.ID = generateID()
+			invoice.Number = fmt.Sprintf("INV-%s-%04d", periodStart.Format("200601"), sequence)
+		}
+		invoice.CreatedBy = createdBy
+		invoice.CreatedAt = time.Now()
+
+		models.Invoices[invoice.ID] = invoice
+		result.Invoices = append(result.Invoices, invoice)
+	}
+	return result
+}
+
+// RunMonthlyInvoices generates invoices for the previous calendar month
+func RunMonthlyInvoices() {
+	now := time.Now()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	result := GenerateInvoices(thisMonth.AddDate(0, -1, 0), "")
+	log.Printf("Invoice run for %s: %d invoices, %d vendors skipped",
+		result.PeriodStart.Format("2006-01"), len(result.Invoices), len(result.Skipped))
+}
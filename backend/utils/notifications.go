@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"time"
+	"vendor-management/models"
+)
+
+// Notify stores an in-app notification for a user
+func Notify(userID, notificationType, refID, message string) *models.Notification {
+	notification := &models.Notification{
+		ID:        generateID(),
+		UserID:    userID,
+		Type:      notificationType,
+		RefID:     refID,
+		Message:   message,
+		CreatedAt: time.Now(),
+	}
+	models.Notifications[notification.ID] = notification
+	return notification
+}
+
+// NotifyAdmins sends the same notification to every admin user
+func NotifyAdmins(notificationType, refID, message string) {
+	for _, user := range models.Users {
+		if user.Role == models.AdminRole {
+			Notify(user.ID, notificationType, refID, message)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"vendor-management/config"
+	"vendor-management/models"
+)
+
+// PurchaseOrderUtilization returns the consumed share of the ceiling as a
+// percentage
+func PurchaseOrderUtilization(po *models.PurchaseOrder) float64 {
+	if po.Amount <= 0 {
+		return 0
+	}
+	return po.Consumed / po.Amount * 100
+}
+
+// MatchPurchaseOrder finds the open purchase order an invoice is billed
+// against: same vendor and currency, covering the invoice's project and
+// period. When several match, the one that started first among those with
+// enough left for the invoice is used. If none has enough, the one that
+// started first is returned, so that consuming it reports the shortfall.
+func MatchPurchaseOrder(invoice *models.Invoice) (*models.PurchaseOrder, bool) {
+	candidates := make([]*models.PurchaseOrder, 0)
+	for _, po := range models.PurchaseOrders {
+		if po.Status != models.PurchaseOrderOpen || po.VendorID != invoice.VendorID || po.Currency != invoice.Currency {
+			continue
+		}
+		if po.ProjectID != "" && po.ProjectID != invoice.ProjectID {
+			continue
+		}
+		if invoice.PeriodEnd.Before(po.StartDate) || (!po.EndDate.IsZero() && invoice.PeriodStart.After(po.EndDate)) {
+			continue
+		}
+		candidates = append(candidates, po)
+	}
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if !candidates[i].StartDate.Equal(candidates[j].StartDate) {
+			return candidates[i].StartDate.Before(candidates[j].StartDate)
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+	for _, po := range candidates {
+		if invoice.Subtotal <= RoundMoney(po.Amount-po.Consumed) {
+			return po, true
+		}
+	}
+	return candidates[0], true
+}
+
+// ConsumePurchaseOrder records an approved invoice against the purchase
+// order. Invoices are consumed pre-tax and may not exceed the remaining
+// ceiling.
+func ConsumePurchaseOrder(po *models.PurchaseOrder, invoice *models.Invoice) error {
+	if invoice.PurchaseOrderID != "" {
+		return errors.New("Invoice has already been consumed")
+	}
+	remaining := RoundMoney(po.Amount - po.Consumed)
+	if invoice.Subtotal > remaining {
+		return fmt.Errorf("Invoice exceeds the remaining %.2f %s on purchase order %s", remaining, po.Currency, po.Number)
+	}
+
+	po.Consumed = RoundMoney(po.Consumed + invoice.Subtotal)
+	po.Consumptions = append(po.Consumptions, models.PurchaseOrderConsumption{
+		InvoiceID:  invoice.ID,
+		Amount:     invoice.Subtotal,
+		ConsumedAt: time.Now(),
+	})
+	invoice.PurchaseOrderID = po.ID
+
+	CheckPurchaseOrderThresholds(po)
+	return nil
+}
+
+// CrossedThreshold returns the highest alert threshold the purchase order's
+// utilization has reached, or 0 if none
+func CrossedThreshold(po *models.PurchaseOrder) int {
+	utilization := PurchaseOrderUtilization(po)
+	crossed := 0
+	for _, threshold := range config.POAlertThresholds {
+		if utilization >= float64(threshold) {
+			crossed = threshold
+		}
+	}
+	return crossed
+}
+
+// CheckPurchaseOrderThresholds alerts admins and the project owner once for
+// the highest utilization threshold the purchase order has crossed
+func CheckPurchaseOrderThresholds(po *models.PurchaseOrder) {
+	crossed := CrossedThreshold(po)
+	if crossed <= po.AlertedThreshold {
+		return
+	}
+	po.AlertedThreshold = crossed
+
+	utilization := PurchaseOrderUtilization(po)
+	message := fmt.Sprintf("Purchase order %s is %.0f%% utilized (%.2f of %.2f %s)",
+		po.Number, utilization, po.Consumed, po.Amount, po.Currency)
+	NotifyAdmins("po_threshold", po.ID, message)
+	if project, exists := models.Projects[po.ProjectID]; exists && project.OwnerID != "" {
+		if owner, exists := models.Users[project.OwnerID]; exists && owner.Role != models.AdminRole {
+			Notify(owner.ID, "po_threshold", po.ID, message)
+		}
+	}
+}
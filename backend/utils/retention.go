@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+	"vendor-management/models"
+	"vendor-management/search"
+)
+
+// UnderLegalHold reports whether a document, or the vendor it belongs to, is
+// under legal hold
+func UnderLegalHold(doc *models.Document) bool {
+	if doc.LegalHold != nil {
+		return true
+	}
+	vendor, exists := models.Vendors[doc.VendorID]
+	return exists && vendor.LegalHold != nil
+}
+
+// vendorHasHeldDocuments reports whether any document of the vendor is under
+// its own legal hold
+func vendorHasHeldDocuments(vendorID string) bool {
+	for _, doc := range models.Documents {
+		if doc.VendorID == vendorID && doc.LegalHold != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// RetentionEnd returns the rule that applies to a document and the end of its
+// retention period. The rule is nil when the document is kept indefinitely.
+func RetentionEnd(doc *models.Document) (*models.RetentionRule, time.Time) {
+	vendor, exists := models.Vendors[doc.VendorID]
+	if !exists || vendor.EndDate.IsZero() {
+		return nil, time.Time{}
+	}
+	for _, rule := range models.RetentionRules {
+		if rule.DocumentType == doc.Type {
+			return rule, vendor.EndDate.AddDate(rule.Years, rule.Months, 0)
+		}
+	}
+	return nil, time.Time{}
+}
+
+// forgetDocument removes every record of a document whose files have been
+// deleted
+func forgetDocument(doc *models.Document) {
+	delete(models.Documents, doc.ID)
+	search.Documents.Remove(doc.ID)
+	for fileID, file := range models.QuarantinedFiles {
+		if file.DocumentID == doc.ID {
+			delete(models.QuarantinedFiles, fileID)
+		}
+	}
+	if vendor, exists := models.Vendors[doc.VendorID]; exists {
+		documents := make([]models.Document, 0, len(vendor.Documents))
+		for _, d := range vendor.Documents {
+			if d.ID != doc.ID {
+				documents = append(documents, d)
+			}
+		}
+		vendor.Documents = documents
+	}
+}
+
+// DisposeExpiredDocuments destroys the files and records of every document,
+// including those in the trash, whose retention period has ended and which is
+// not under legal hold. A disposal certificate is kept for each.
+func DisposeExpiredDocuments() []*models.DisposalCertificate {
+	now := time.Now()
+	certificates := make([]*models.DisposalCertificate, 0)
+
+	for id, doc := range models.Documents {
+		rule, retainUntil := RetentionEnd(doc)
+		if rule == nil || retainUntil.After(now) || UnderLegalHold(doc) {
+			continue
+		}
+		if err := removeDocumentFiles(doc); err != nil {
+			log.Printf("Failed to dispose of document %s: %v", id, err)
+			continue
+		}
+
+		vendor := models.Vendors[doc.VendorID]
+		certificate := &models.DisposalCertificate{
+			ID:           generateID(),
+			DocumentID:   id,
+			VendorID:     vendor.ID,
+			VendorName:   vendor.CompanyName,
+			DocumentType: doc.Type,
+			Name:         doc.Name,
+			Files:        make([]models.DisposedFile, len(doc.Versions)),
+			RuleID:       rule.ID,
+			EndDate:      vendor.EndDate,
+			RetainUntil:  retainUntil,
+			DisposedAt:   now,
+		}
+		for i, version := range doc.Versions {
+			certificate.Files[i] = models.DisposedFile{
+				Version:  version.Version,
+				Name:     version.Name,
+				Size:     version.Size,
+				Checksum: version.Checksum,
+			}
+		}
+		models.DisposalCertificates[certificate.ID] = certificate
+		certificates = append(certificates, certificate)
+		forgetDocument(doc)
+	}
+
+	sort.Slice(certificates, func(i, j int) bool {
+		return certificates[i].DocumentID < certificates[j].DocumentID
+	})
+	if len(certificates) > 0 {
+		NotifyAdmins("documents_disposed", "", fmt.Sprintf("%d documents past their retention period were disposed of", len(certificates)))
+	}
+	log.Printf("Disposed of %d documents past their retention period", len(certificates))
+	return certificates
+}
+
+// RunDisposal is the cron entry point for DisposeExpiredDocuments
+func RunDisposal() {
+	DisposeExpiredDocuments()
+}
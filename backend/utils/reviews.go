@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"time"
+	"vendor-management/config"
+	"vendor-management/models"
+)
+
+// SendReviewReminders notifies reviewers about active vendors whose last
+// performance review period ended more than the review interval ago, or who
+// have never been reviewed since joining. The department owner is notified
+// when there is one, otherwise every admin. Each vendor is reminded at most
+// once per review cycle.
+func SendReviewReminders() {
+	now := time.Now()
+	reminders := 0
+	for _, vendor := range models.Vendors {
+		if vendor.Status != "active" || vendor.DeletedAt != nil {
+			continue
+		}
+
+		lastReviewed := vendor.JoiningDate
+		for _, review := range models.PerformanceReviews[vendor.ID] {
+			if review.PeriodEnd.After(lastReviewed) {
+				lastReviewed = review.PeriodEnd
+			}
+		}
+		if now.Sub(lastReviewed) < config.ReviewInterval {
+			continue
+		}
+		if reminderSentSince(vendor.ID, lastReviewed) {
+			continue
+		}
+
+		message := fmt.Sprintf("Performance review due for %s (%s)", vendor.CompanyName, vendor.ProjectName)
+		if department, ok := models.Departments[vendor.DepartmentID]; ok && department.OwnerID != "" {
+			Notify(department.OwnerID, "review_due", vendor.ID, message)
+		} else {
+			NotifyAdmins("review_due", vendor.ID, message)
+		}
+		reminders++
+	}
+	log.Printf("Sent %d performance review reminders", reminders)
+}
+
+func reminderSentSince(vendorID string, since time.Time) bool {
+	for _, notification := range models.Notifications {
+		if notification.Type == "review_due" && notification.RefID == vendorID && notification.CreatedAt.After(since) {
+			return true
+		}
+	}
+	return false
+}
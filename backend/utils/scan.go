@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"time"
+	"vendor-management/models"
+	"vendor-management/preview"
+	"vendor-management/scanner"
+	"vendor-management/storage"
+)
+
+// SyncVendorDocument refreshes the vendor's copy of the document
+func SyncVendorDocument(doc *models.Document) {
+	if vendor, exists := models.Vendors[doc.VendorID]; exists {
+		for i := range vendor.Documents {
+			if vendor.Documents[i].ID == doc.ID {
+				vendor.Documents[i] = *doc
+			}
+		}
+	}
+}
+
+// QuarantineFile stores an infected file under the quarantine/ prefix, where
+// no download route can reach it, records it and alerts admins
+func QuarantineFile(file *models.QuarantinedFile, r io.Reader, size int64) error {
+	file.ID = generateID()
+	file.StorageKey = fmt.Sprintf("quarantine/%s/%s%s", file.OwnerID, file.ID, path.Ext(file.Name))
+	file.QuarantinedAt = time.Now()
+	if err := storage.Store.Put(file.StorageKey, r, size); err != nil {
+		return err
+	}
+
+	models.QuarantinedFiles[file.ID] = file
+	NotifyAdmins("malware_detected", file.ID, fmt.Sprintf("%s was quarantined: %s", file.Name, file.Signature))
+	return nil
+}
+
+// scanStoredVersion scans one stored file, moving it to quarantine when it
+// is infected
+func scanStoredVersion(doc *models.Document, version *models.DocumentVersion) error {
+	obj, err := storage.Store.Open(version.StorageKey)
+	if err != nil {
+		return err
+	}
+	result, err := scanner.Default.Scan(obj)
+	obj.Close()
+	if err != nil {
+		return err
+	}
+	if !result.Infected {
+		version.ScanStatus = models.ScanClean
+		return nil
+	}
+
+	obj, err = storage.Store.Open(version.StorageKey)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	ownerID := doc.VendorID
+	if ownerID == "" {
+		ownerID = doc.CompanyID
+	}
+	file := &models.QuarantinedFile{
+		DocumentID: doc.ID,
+		Version:    version.Version,
+		OwnerID:    ownerID,
+		Name:       version.Name,
+		Signature:  result.Signature,
+		UploadedBy: version.UploadedBy,
+	}
+	if err := QuarantineFile(file, obj, obj.Size()); err != nil {
+		return err
+	}
+	if err := storage.Store.Delete(version.StorageKey); err != nil {
+		log.Printf("Failed to remove quarantined file %s: %v", version.StorageKey, err)
+	}
+	version.StorageKey = file.StorageKey
+	version.ScanStatus = models.ScanInfected
+	return nil
+}
+
+// RescanDocuments scans every stored file that has not been scanned yet,
+// such as files uploaded while the scanner was unavailable or before
+// scanning was introduced
+func RescanDocuments() {
+	scanned, infected := 0, 0
+	for _, doc := range models.Documents {
+		for i := range doc.Versions {
+			version := &doc.Versions[i]
+			if version.ScanStatus == models.ScanClean || version.ScanStatus == models.ScanInfected {
+				continue
+			}
+			err := scanStoredVersion(doc, version)
+			if errors.Is(err, scanner.ErrNotConfigured) {
+				log.Printf("Skipped scanning pending document files: %v", err)
+				return
+			}
+			if err != nil {
+				log.Printf("Failed to scan file %s of document %s: %v", version.StorageKey, doc.ID, err)
+				continue
+			}
+			scanned++
+			if version.ScanStatus == models.ScanInfected {
+				infected++
+			} else {
+				preview.Enqueue(version.StorageKey, version.ContentType)
+			}
+			if version.Version == doc.CurrentVersion {
+				doc.StorageKey = version.StorageKey
+				doc.ScanStatus = version.ScanStatus
+				SyncVendorDocument(doc)
+			}
+		}
+	}
+	log.Printf("Scanned %d pending document files, %d infected", scanned, infected)
+}
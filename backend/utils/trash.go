@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"log"
+	"time"
+	"vendor-management/config"
+	"vendor-management/models"
+	"vendor-management/preview"
+	"vendor-management/storage"
+)
+
+// PurgeTrash permanently removes vendors, assets and documents that have been
+// in the trash for longer than the retention period, including the uploaded
+// files of purged documents. Documents belonging to a purged vendor are
+// purged with it. Nothing under legal hold is purged, nor is a vendor with a
+// held document or a document whose files could not be removed.
+func PurgeTrash() {
+	cutoff := time.Now().Add(-config.TrashRetention)
+	expired := func(deletedAt *time.Time) bool {
+		return deletedAt != nil && deletedAt.Before(cutoff)
+	}
+
+	purgedVendors := make(map[string]bool)
+	for id, vendor := range models.Vendors {
+		if expired(vendor.DeletedAt) && vendor.LegalHold == nil && !vendorHasHeldDocuments(id) {
+			purgedVendors[id] = true
+		}
+	}
+
+	// Vendors are kept until all of their documents are gone, so the next run
+	// retries a document whose files could not be removed
+	unpurgedDocuments := make(map[string]bool)
+	documents := 0
+	for id, doc := range models.Documents {
+		if !expired(doc.DeletedAt) && !purgedVendors[doc.VendorID] {
+			continue
+		}
+		if UnderLegalHold(doc) {
+			continue
+		}
+		if err := removeDocumentFiles(doc); err != nil {
+			log.Printf("Failed to remove file for document %s: %v", id, err)
+			unpurgedDocuments[doc.VendorID] = true
+			continue
+		}
+		forgetDocument(doc)
+		documents++
+	}
+
+	assets := 0
+	for id, asset := range models.Assets {
+		if expired(asset.DeletedAt) {
+			delete(models.Assets, id)
+			assets++
+		}
+	}
+
+	for id := range purgedVendors {
+		if unpurgedDocuments[id] {
+			delete(purgedVendors, id)
+			continue
+		}
+		delete(models.Vendors, id)
+		delete(models.AttendanceRecords, id)
+		delete(models.VendorRevisions, id)
+		delete(models.ProjectAssignments, id)
+		delete(models.PerformanceReviews, id)
+		delete(models.RateCards, id)
+		delete(models.ComplianceStatuses, id)
+	}
+
+	log.Printf("Purged %d vendors, %d assets and %d documents from trash", len(purgedVendors), assets, documents)
+}
+
+// removeDocumentFiles deletes the files of every version of the document,
+// together with their cached previews
+func removeDocumentFiles(doc *models.Document) error {
+	keys := []string{doc.StorageKey}
+	for _, version := range doc.Versions {
+		if version.StorageKey != doc.StorageKey {
+			keys = append(keys, version.StorageKey)
+		}
+	}
+	for _, key := range keys {
+		if err := storage.Store.Delete(key); err != nil {
+			return err
+		}
+		if err := preview.Remove(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"time"
+	"vendor-management/models"
+)
+
+// RecordVendorRevision appends a snapshot of the vendor to its revision
+// history. changedBy is empty for changes made by background jobs.
+func RecordVendorRevision(vendor *models.Vendor, changedBy string) {
+	snapshot := *vendor
+	snapshot.Documents = nil
+	snapshot.Assets = nil
+
+	revisions := models.VendorRevisions[vendor.ID]
+	models.VendorRevisions[vendor.ID] = append(revisions, &models.VendorRevision{
+		VendorID:  vendor.ID,
+		Version:   len(revisions) + 1,
+		Vendor:    snapshot,
+		ChangedBy: changedBy,
+		ChangedAt: time.Now(),
+	})
+}